@@ -125,6 +125,10 @@ func initCommon() {
 		config.DiskUsage.Enabled = true
 	}
 
+	if err := config.Validate(); err != nil {
+		logr.Fatal(err)
+	}
+
 	export = exporter.NewExporter(log, config)
 	if err := export.Init(ctx); err != nil {
 		logrus.Fatal(err)
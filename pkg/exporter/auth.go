@@ -0,0 +1,84 @@
+package exporter
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Enabled returns true if any TLS settings are configured.
+func (t *TLSConfig) Enabled() bool {
+	return t.CAFile != "" || t.CertFile != "" || t.KeyFile != "" || t.ServerName != "" || t.InsecureSkipVerify
+}
+
+// Load builds the client TLS config. It returns nil if no TLS settings are configured.
+func (t *TLSConfig) Load() (*tls.Config, error) {
+	if !t.Enabled() {
+		return nil, nil
+	}
+
+	//nolint:gosec // skipping verification is an explicit opt-in.
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+
+	if t.CAFile != "" {
+		ca, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", t.CAFile)
+		}
+
+		config.RootCAs = pool
+	}
+
+	if t.CertFile != "" || t.KeyFile != "" {
+		if t.CertFile == "" || t.KeyFile == "" {
+			return nil, errors.New("both certFile and keyFile are required for client certificates")
+		}
+
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// requestHeaders returns the configured headers of the consensus node, including the
+// Authorization header for basic auth. Configuring both basic auth and an Authorization header
+// is an error.
+func (c *ConsensusNode) requestHeaders() (map[string]string, error) {
+	headers := make(map[string]string, len(c.Headers)+1)
+
+	for k, v := range c.Headers {
+		headers[k] = v
+	}
+
+	if c.BasicAuth != nil {
+		for k := range headers {
+			if strings.EqualFold(k, "Authorization") {
+				return nil, errors.New("consensus.basicAuth and an Authorization header in consensus.headers are mutually exclusive")
+			}
+		}
+
+		credentials := base64.StdEncoding.EncodeToString([]byte(c.BasicAuth.Username + ":" + c.BasicAuth.Password))
+
+		headers["Authorization"] = "Basic " + credentials
+	}
+
+	return headers, nil
+}
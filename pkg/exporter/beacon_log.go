@@ -0,0 +1,58 @@
+package exporter
+
+import (
+	"strings"
+
+	consensusjobs "github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/jobs"
+	"github.com/sirupsen/logrus"
+)
+
+// unhandledTopicMessage is the error the beacon library logs for every event of a topic it does
+// not decode itself.
+const unhandledTopicMessage = "Failed to handle event: unknown event topic "
+
+// unhandledTopicFormatter drops the unhandledTopicMessage errors of known topics. The jobs receive
+// these events through OnEvent, so the errors are noise.
+type unhandledTopicFormatter struct {
+	logrus.Formatter
+}
+
+func (f *unhandledTopicFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if entry.Level == logrus.ErrorLevel && strings.HasPrefix(entry.Message, unhandledTopicMessage) {
+		if _, ok := consensusjobs.KnownEventTopics[strings.TrimPrefix(entry.Message, unhandledTopicMessage)]; ok {
+			return nil, nil
+		}
+	}
+
+	return f.Formatter.Format(entry)
+}
+
+// beaconLogger returns the logger passed to the beacon library, which writes to the same output as
+// log but without the unhandled topic errors.
+func beaconLogger(log logrus.FieldLogger) logrus.FieldLogger {
+	var (
+		base   *logrus.Logger
+		fields logrus.Fields
+	)
+
+	switch l := log.(type) {
+	case *logrus.Logger:
+		base = l
+	case *logrus.Entry:
+		base = l.Logger
+		fields = l.Data
+	default:
+		return log
+	}
+
+	filtered := &logrus.Logger{
+		Out:          base.Out,
+		Hooks:        base.Hooks,
+		Formatter:    &unhandledTopicFormatter{Formatter: base.Formatter},
+		ReportCaller: base.ReportCaller,
+		Level:        base.GetLevel(),
+		ExitFunc:     base.ExitFunc,
+	}
+
+	return filtered.WithFields(fields)
+}
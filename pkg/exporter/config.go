@@ -1,10 +1,16 @@
 package exporter
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/ethpandaops/beacon/pkg/human"
+	consensusjobs "github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/jobs"
 	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/docker"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/execution/jobs"
 )
 
 // Config holds the configuration for the ethereum sync status tool.
@@ -19,6 +25,12 @@ type Config struct {
 	Docker DockerConfig `yaml:"docker"`
 	// Pair determines if the pair metrics should be exported.
 	Pair PairConfig `yaml:"pair"`
+	// Validator is the validator client to monitor via its keymanager API.
+	Validator ValidatorClient `yaml:"validator"`
+	// RemoteSigner is the Web3Signer instance to monitor.
+	RemoteSigner RemoteSigner `yaml:"remoteSigner"`
+	// MevBoost configures monitoring of mev-boost and its relays. Requires the consensus node.
+	MevBoost consensusjobs.MevBoostConfig `yaml:"mevBoost"`
 }
 
 // ConsensusNode represents a single ethereum consensus client.
@@ -27,11 +39,62 @@ type ConsensusNode struct {
 	Name        string      `yaml:"name"`
 	URL         string      `yaml:"url"`
 	EventStream EventStream `yaml:"eventStream"`
+	// Validators are the validators whose performance is monitored.
+	Validators []consensusjobs.ValidatorConfig `yaml:"validators"`
+	// SlotTracker configures missed slot, orphaned block and chain reorg tracking.
+	SlotTracker consensusjobs.SlotTrackerConfig `yaml:"slotTracker"`
+	// Blobs configures blob and data column availability metrics for head blocks.
+	Blobs consensusjobs.BlobsConfig `yaml:"blobs"`
+	// Peers configures the peers by client metrics.
+	Peers consensusjobs.PeersConfig `yaml:"peers"`
+	// StateSummary configures chain-wide validator counts, queue lengths and churn limits.
+	StateSummary consensusjobs.StateSummaryConfig `yaml:"stateSummary"`
+	// LightClient configures monitoring of the light client endpoints served by the node.
+	LightClient consensusjobs.LightClientConfig `yaml:"lightClient"`
+	// ExecutionPayload configures head execution payload, optimistic import and proposal timing metrics.
+	ExecutionPayload consensusjobs.ExecutionPayloadConfig `yaml:"executionPayload"`
+	// Participation configures network-wide sync committee and attestation participation metrics.
+	Participation consensusjobs.ParticipationConfig `yaml:"participation"`
+	// Operations configures slashing, voluntary exit and BLS to execution change metrics.
+	Operations consensusjobs.OperationsConfig `yaml:"operations"`
+	// Headers are sent with every request to the beacon node, e.g. for bearer token authentication.
+	Headers map[string]string `yaml:"headers"`
+	// BasicAuth configures HTTP basic authentication against the beacon node.
+	BasicAuth *BasicAuth `yaml:"basicAuth"`
+	// TLS configures the TLS client used to connect to the beacon node. It applies to the beacon API
+	// requests of the beacon library and of the exporter jobs. The event stream and the peer polling
+	// of the beacon library cannot be given a TLS config, so TLS is rejected while the event stream
+	// is enabled and the peer count metrics of the beacon library are not available with TLS.
+	TLS TLSConfig `yaml:"tls"`
+	// CheckpointVerifiers are beacon API URLs of trusted nodes whose finalized checkpoint is compared with the node's.
+	CheckpointVerifiers []string `yaml:"checkpointVerifiers"`
+}
+
+// BasicAuth holds HTTP basic authentication credentials.
+type BasicAuth struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// TLSConfig configures a TLS client, e.g. to trust a private CA or to present a client certificate.
+type TLSConfig struct {
+	CAFile             string `yaml:"caFile"`
+	CertFile           string `yaml:"certFile"`
+	KeyFile            string `yaml:"keyFile"`
+	ServerName         string `yaml:"serverName"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify"`
 }
 
 type EventStream struct {
-	Enabled *bool    `yaml:"enabled"`
-	Topics  []string `yaml:"topics"`
+	Enabled *bool `yaml:"enabled"`
+	// Topics are checked against the known topics when the config is loaded, and against the forks
+	// that are active on the network of the beacon node at startup.
+	Topics []string `yaml:"topics"`
+	// SilentEpochs is the number of epochs after which a topic that is expected to fire every
+	// epoch is reported as silent.
+	SilentEpochs uint64 `yaml:"silentEpochs"`
+	// Proxy re-exposes the subscribed topics as server-sent events on /events.
+	Proxy consensusjobs.EventProxyConfig `yaml:"proxy"`
 }
 
 // ExecutionNode represents a single ethereum execution client.
@@ -40,6 +103,38 @@ type ExecutionNode struct {
 	Name    string   `yaml:"name"`
 	URL     string   `yaml:"url"`
 	Modules []string `yaml:"modules"`
+	// ContractCalls are eth_call watches whose results are exported as gauges.
+	ContractCalls []jobs.ContractCall `yaml:"contractCalls"`
+	// DepositContract configures monitoring of the beacon chain deposit contract.
+	DepositContract jobs.DepositContractConfig `yaml:"depositContract"`
+	// ContractEvents are contract events that are counted as new blocks arrive.
+	ContractEvents []jobs.ContractEvent `yaml:"contractEvents"`
+	// Builders configures attribution of head blocks to builders.
+	Builders jobs.BuilderAttributionConfig `yaml:"builders"`
+	// BlockComposition enables transaction type and gas composition metrics for head blocks.
+	BlockComposition jobs.BlockCompositionConfig `yaml:"blockComposition"`
+}
+
+// ValidatorClient configures monitoring of a validator client via its keymanager API.
+type ValidatorClient struct {
+	Enabled bool   `yaml:"enabled"`
+	Name    string `yaml:"name"`
+	URL     string `yaml:"url"`
+	// TokenFile is the file containing the keymanager API bearer token.
+	TokenFile string `yaml:"tokenFile"`
+	// ExpectedKeys are the pubkeys the validator client is expected to have loaded.
+	ExpectedKeys []string       `yaml:"expectedKeys"`
+	Interval     human.Duration `yaml:"interval"`
+}
+
+// RemoteSigner configures monitoring of a Web3Signer instance.
+type RemoteSigner struct {
+	Enabled bool   `yaml:"enabled"`
+	Name    string `yaml:"name"`
+	URL     string `yaml:"url"`
+	// ExpectedKeys are the pubkeys the signer is expected to have loaded.
+	ExpectedKeys []string       `yaml:"expectedKeys"`
+	Interval     human.Duration `yaml:"interval"`
 }
 
 // DiskUsage configures the exporter to expose disk usage stats for these directories.
@@ -87,6 +182,77 @@ type PairConfig struct {
 	Enabled bool `yaml:"enabled"`
 }
 
+// Validate returns an error if the configuration is invalid.
+func (c *Config) Validate() error {
+	if c.Execution.Enabled {
+		if err := jobs.ValidateContractCalls(c.Execution.ContractCalls); err != nil {
+			return fmt.Errorf("invalid execution.contractCalls: %w", err)
+		}
+	}
+
+	if c.Consensus.Enabled && c.Consensus.EventStream.Enabled != nil && *c.Consensus.EventStream.Enabled {
+		if err := consensusjobs.ValidateEventTopics(c.Consensus.EventStream.Topics); err != nil {
+			return fmt.Errorf("invalid consensus.eventStream.topics: %w", err)
+		}
+	}
+
+	if c.Consensus.Enabled {
+		// The event stream client of go-eth2-client builds its own transport, so it would connect
+		// without the TLS config.
+		if c.Consensus.TLS.Enabled() && c.Consensus.eventStreamEnabled() {
+			return errors.New("consensus.tls is not supported with the beacon event stream, which is enabled by consensus.eventStream, blobs, slotTracker, executionPayload, participation and operations")
+		}
+
+		if _, err := c.Consensus.requestHeaders(); err != nil {
+			return err
+		}
+
+		if _, err := c.Consensus.checkpointVerifierURLs(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// eventStreamEnabled returns true if the beacon event stream is enabled, either explicitly or
+// because an enabled job depends on it.
+func (c *ConsensusNode) eventStreamEnabled() bool {
+	if c.EventStream.Enabled != nil && *c.EventStream.Enabled {
+		return true
+	}
+
+	return c.Blobs.Enabled ||
+		c.SlotTracker.Enabled ||
+		c.ExecutionPayload.Enabled ||
+		c.Participation.Enabled ||
+		c.Operations.Enabled
+}
+
+// checkpointVerifierURLs returns the checkpoint verifier URLs keyed by the name used in the
+// verifier label, which is the host and path of the URL so that credentials and query parameters
+// do not end up in labels. Verifiers that end up with the same name are an error, as they would
+// overwrite each other.
+func (c *ConsensusNode) checkpointVerifierURLs() (map[string]string, error) {
+	urls := make(map[string]string, len(c.CheckpointVerifiers))
+
+	for _, raw := range c.CheckpointVerifiers {
+		name := raw
+
+		if u, err := url.Parse(raw); err == nil && u.Host != "" {
+			name = u.Host + strings.TrimSuffix(u.Path, "/")
+		}
+
+		if existing, ok := urls[name]; ok {
+			return nil, fmt.Errorf("consensus.checkpointVerifiers %q and %q are the same verifier %q", existing, raw, name)
+		}
+
+		urls[name] = raw
+	}
+
+	return urls, nil
+}
+
 // DefaultConfig represents a sane-default configuration.
 func DefaultConfig() *Config {
 	f := false
@@ -107,6 +273,22 @@ func DefaultConfig() *Config {
 				Topics:  []string{},
 			},
 		},
+		Validator: ValidatorClient{
+			Enabled: false,
+			Name:    "validator",
+			URL:     "http://localhost:5062",
+			Interval: human.Duration{
+				Duration: 60 * time.Second,
+			},
+		},
+		RemoteSigner: RemoteSigner{
+			Enabled: false,
+			Name:    "remote-signer",
+			URL:     "http://localhost:9000",
+			Interval: human.Duration{
+				Duration: 30 * time.Second,
+			},
+		},
 		DiskUsage: DiskUsage{
 			Enabled:     false,
 			Directories: []string{},
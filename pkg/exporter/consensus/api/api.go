@@ -0,0 +1,524 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api/types"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrNotFound is returned when the beacon node responds with a 404.
+var ErrNotFound = errors.New("not found")
+
+// ConsensusClient is an interface for executing Beacon API calls that are not covered by the beacon library.
+type ConsensusClient interface {
+	// AttestationRewards returns the attestation rewards for the given validators in an epoch.
+	AttestationRewards(ctx context.Context, epoch phase0.Epoch, indices []phase0.ValidatorIndex) (*types.AttestationRewards, error)
+	// SyncCommitteeRewards returns the sync committee rewards for the given validators in a block.
+	SyncCommitteeRewards(ctx context.Context, blockID string, indices []phase0.ValidatorIndex) ([]types.SyncCommitteeReward, error)
+	// SyncCommittee returns the sync committee for the given state and epoch.
+	SyncCommittee(ctx context.Context, stateID string, epoch *phase0.Epoch) (*types.SyncCommittee, error)
+	// SyncCommitteeDuties returns the sync committee duties of the given validators for the period containing the epoch.
+	SyncCommitteeDuties(ctx context.Context, epoch phase0.Epoch, indices []phase0.ValidatorIndex) ([]types.SyncCommitteeDuty, error)
+	// NodeIdentity returns the identity of the beacon node.
+	NodeIdentity(ctx context.Context) (*types.Identity, error)
+	// DataColumnSidecars returns the data column sidecars the node custodies for the given block id.
+	DataColumnSidecars(ctx context.Context, blockID string) ([]types.DataColumnSidecar, error)
+	// SyncStatus returns the sync status of the node.
+	SyncStatus(ctx context.Context) (*types.SyncStatus, error)
+	// Peers returns the peers of the node.
+	Peers(ctx context.Context) ([]types.Peer, error)
+	// Peer returns a single peer of the node.
+	Peer(ctx context.Context, peerID string) (*types.Peer, error)
+	// ForkSchedule returns the fork schedule of the node.
+	ForkSchedule(ctx context.Context) ([]types.Fork, error)
+	// Spec returns the chain spec of the node. Values that are not strings are kept as decoded JSON.
+	Spec(ctx context.Context) (map[string]any, error)
+	// Genesis returns the genesis of the chain.
+	Genesis(ctx context.Context) (*types.Genesis, error)
+	// FinalityCheckpoints returns the finality checkpoints of the given state.
+	FinalityCheckpoints(ctx context.Context, stateID string) (*types.FinalityCheckpoints, error)
+	// PendingDeposits returns the pending deposits of the given state, or ErrNotFound before Electra.
+	PendingDeposits(ctx context.Context, stateID string) ([]types.PendingDeposit, error)
+	// PendingConsolidations returns the pending consolidations of the given state, or ErrNotFound before Electra.
+	PendingConsolidations(ctx context.Context, stateID string) ([]types.PendingConsolidation, error)
+	// PendingPartialWithdrawals returns the pending partial withdrawals of the given state, or ErrNotFound before Electra.
+	PendingPartialWithdrawals(ctx context.Context, stateID string) ([]types.PendingPartialWithdrawal, error)
+	// LightClientFinalityUpdate returns the latest light client finality update.
+	LightClientFinalityUpdate(ctx context.Context) (*types.LightClientUpdate, error)
+	// LightClientOptimisticUpdate returns the latest light client optimistic update.
+	LightClientOptimisticUpdate(ctx context.Context) (*types.LightClientUpdate, error)
+	// LightClientBootstrap returns the light client bootstrap for the block root.
+	LightClientBootstrap(ctx context.Context, root phase0.Root) (*types.LightClientBootstrap, error)
+	// PoolProposerSlashings returns the proposer slashings in the operation pool.
+	PoolProposerSlashings(ctx context.Context) ([]types.ProposerSlashing, error)
+	// PoolAttesterSlashings returns the attester slashings in the operation pool.
+	PoolAttesterSlashings(ctx context.Context) ([]types.AttesterSlashing, error)
+	// PoolVoluntaryExits returns the voluntary exits in the operation pool.
+	PoolVoluntaryExits(ctx context.Context) ([]types.SignedVoluntaryExit, error)
+	// PoolBLSToExecutionChanges returns the BLS to execution changes in the operation pool.
+	PoolBLSToExecutionChanges(ctx context.Context) ([]types.SignedBLSToExecutionChange, error)
+	// BlockHeader returns the block header for the given block id, or ErrNotFound if there is no block.
+	BlockHeader(ctx context.Context, blockID string) (*types.BlockHeader, error)
+}
+
+type consensusClient struct {
+	url     string
+	log     logrus.FieldLogger
+	client  http.Client
+	headers map[string]string
+}
+
+// NewConsensusClient creates a new ConsensusClient. The headers are sent with every request and
+// the TLS config is optional.
+func NewConsensusClient(ctx context.Context, log logrus.FieldLogger, url string, headers map[string]string, tlsConfig *tls.Config) ConsensusClient {
+	client := http.Client{
+		Timeout: time.Second * 10,
+	}
+
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+
+		client.Transport = transport
+	}
+
+	return &consensusClient{
+		url:     strings.TrimSuffix(url, "/"),
+		log:     log,
+		client:  client,
+		headers: headers,
+	}
+}
+
+type apiResponse struct {
+	Data json.RawMessage `json:"data"`
+}
+
+func (c *consensusClient) do(ctx context.Context, method, path string, body any) (json.RawMessage, error) {
+	var reader io.Reader
+
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+
+		reader = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	rsp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code: %d", rsp.StatusCode)
+	}
+
+	data, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(apiResponse)
+	if err := json.Unmarshal(data, resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+func (c *consensusClient) get(ctx context.Context, path string) (json.RawMessage, error) {
+	return c.do(ctx, http.MethodGet, path, nil)
+}
+
+func (c *consensusClient) post(ctx context.Context, path string, body any) (json.RawMessage, error) {
+	return c.do(ctx, http.MethodPost, path, body)
+}
+
+func indicesToStrings(indices []phase0.ValidatorIndex) []string {
+	ids := make([]string, 0, len(indices))
+	for _, index := range indices {
+		ids = append(ids, fmt.Sprintf("%d", index))
+	}
+
+	return ids
+}
+
+func (c *consensusClient) AttestationRewards(ctx context.Context, epoch phase0.Epoch, indices []phase0.ValidatorIndex) (*types.AttestationRewards, error) {
+	data, err := c.post(ctx, fmt.Sprintf("/eth/v1/beacon/rewards/attestations/%d", epoch), indicesToStrings(indices))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &types.AttestationRewards{}
+	if err := json.Unmarshal(data, rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) SyncCommitteeRewards(ctx context.Context, blockID string, indices []phase0.ValidatorIndex) ([]types.SyncCommitteeReward, error) {
+	data, err := c.post(ctx, fmt.Sprintf("/eth/v1/beacon/rewards/sync_committee/%s", blockID), indicesToStrings(indices))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := []types.SyncCommitteeReward{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) SyncCommittee(ctx context.Context, stateID string, epoch *phase0.Epoch) (*types.SyncCommittee, error) {
+	path := fmt.Sprintf("/eth/v1/beacon/states/%s/sync_committees", stateID)
+	if epoch != nil {
+		path += fmt.Sprintf("?epoch=%d", *epoch)
+	}
+
+	data, err := c.get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &types.SyncCommittee{}
+	if err := json.Unmarshal(data, rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) BlockHeader(ctx context.Context, blockID string) (*types.BlockHeader, error) {
+	data, err := c.get(ctx, fmt.Sprintf("/eth/v1/beacon/headers/%s", blockID))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &types.BlockHeader{}
+	if err := json.Unmarshal(data, rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) SyncCommitteeDuties(ctx context.Context, epoch phase0.Epoch, indices []phase0.ValidatorIndex) ([]types.SyncCommitteeDuty, error) {
+	data, err := c.post(ctx, fmt.Sprintf("/eth/v1/validator/duties/sync/%d", epoch), indicesToStrings(indices))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := []types.SyncCommitteeDuty{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) NodeIdentity(ctx context.Context) (*types.Identity, error) {
+	data, err := c.get(ctx, "/eth/v1/node/identity")
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &types.Identity{}
+	if err := json.Unmarshal(data, rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) DataColumnSidecars(ctx context.Context, blockID string) ([]types.DataColumnSidecar, error) {
+	data, err := c.get(ctx, fmt.Sprintf("/eth/v1/debug/beacon/data_column_sidecars/%s", blockID))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := []types.DataColumnSidecar{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) ForkSchedule(ctx context.Context) ([]types.Fork, error) {
+	data, err := c.get(ctx, "/eth/v1/config/fork_schedule")
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := []types.Fork{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) Spec(ctx context.Context) (map[string]any, error) {
+	data, err := c.get(ctx, "/eth/v1/config/spec")
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := map[string]any{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) Genesis(ctx context.Context) (*types.Genesis, error) {
+	data, err := c.get(ctx, "/eth/v1/beacon/genesis")
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &types.Genesis{}
+	if err := json.Unmarshal(data, rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) SyncStatus(ctx context.Context) (*types.SyncStatus, error) {
+	data, err := c.get(ctx, "/eth/v1/node/syncing")
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &types.SyncStatus{}
+	if err := json.Unmarshal(data, rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) Peers(ctx context.Context) ([]types.Peer, error) {
+	data, err := c.get(ctx, "/eth/v1/node/peers")
+	if err != nil {
+		return nil, err
+	}
+
+	var rsp []types.Peer
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) Peer(ctx context.Context, peerID string) (*types.Peer, error) {
+	data, err := c.get(ctx, fmt.Sprintf("/eth/v1/node/peers/%s", peerID))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &types.Peer{}
+	if err := json.Unmarshal(data, rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) FinalityCheckpoints(ctx context.Context, stateID string) (*types.FinalityCheckpoints, error) {
+	data, err := c.get(ctx, fmt.Sprintf("/eth/v1/beacon/states/%s/finality_checkpoints", stateID))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &types.FinalityCheckpoints{}
+	if err := json.Unmarshal(data, rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) PendingDeposits(ctx context.Context, stateID string) ([]types.PendingDeposit, error) {
+	data, err := c.get(ctx, fmt.Sprintf("/eth/v1/beacon/states/%s/pending_deposits", stateID))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := []types.PendingDeposit{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) PendingConsolidations(ctx context.Context, stateID string) ([]types.PendingConsolidation, error) {
+	data, err := c.get(ctx, fmt.Sprintf("/eth/v1/beacon/states/%s/pending_consolidations", stateID))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := []types.PendingConsolidation{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) PendingPartialWithdrawals(ctx context.Context, stateID string) ([]types.PendingPartialWithdrawal, error) {
+	data, err := c.get(ctx, fmt.Sprintf("/eth/v1/beacon/states/%s/pending_partial_withdrawals", stateID))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := []types.PendingPartialWithdrawal{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) LightClientFinalityUpdate(ctx context.Context) (*types.LightClientUpdate, error) {
+	data, err := c.get(ctx, "/eth/v1/beacon/light_client/finality_update")
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &types.LightClientUpdate{}
+	if err := json.Unmarshal(data, rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) LightClientOptimisticUpdate(ctx context.Context) (*types.LightClientUpdate, error) {
+	data, err := c.get(ctx, "/eth/v1/beacon/light_client/optimistic_update")
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &types.LightClientUpdate{}
+	if err := json.Unmarshal(data, rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) LightClientBootstrap(ctx context.Context, root phase0.Root) (*types.LightClientBootstrap, error) {
+	data, err := c.get(ctx, fmt.Sprintf("/eth/v1/beacon/light_client/bootstrap/%s", root.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &types.LightClientBootstrap{}
+	if err := json.Unmarshal(data, rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) PoolProposerSlashings(ctx context.Context) ([]types.ProposerSlashing, error) {
+	data, err := c.get(ctx, "/eth/v1/beacon/pool/proposer_slashings")
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := []types.ProposerSlashing{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) PoolAttesterSlashings(ctx context.Context) ([]types.AttesterSlashing, error) {
+	data, err := c.get(ctx, "/eth/v2/beacon/pool/attester_slashings")
+	if errors.Is(err, ErrNotFound) {
+		// Nodes without Electra support only serve the v1 endpoint.
+		data, err = c.get(ctx, "/eth/v1/beacon/pool/attester_slashings")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := []types.AttesterSlashing{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) PoolVoluntaryExits(ctx context.Context) ([]types.SignedVoluntaryExit, error) {
+	data, err := c.get(ctx, "/eth/v1/beacon/pool/voluntary_exits")
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := []types.SignedVoluntaryExit{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *consensusClient) PoolBLSToExecutionChanges(ctx context.Context) ([]types.SignedBLSToExecutionChange, error) {
+	data, err := c.get(ctx, "/eth/v1/beacon/pool/bls_to_execution_changes")
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := []types.SignedBLSToExecutionChange{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
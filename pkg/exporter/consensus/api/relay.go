@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api/types"
+)
+
+// RelayClient executes builder API and relay data API calls against mev-boost or a relay.
+type RelayClient interface {
+	// Status returns nil if the builder API reports itself as available.
+	Status(ctx context.Context) error
+	// ValidatorRegistered returns true if the relay holds a registration for the validator.
+	ValidatorRegistered(ctx context.Context, pubkey phase0.BLSPubKey) (bool, error)
+	// PayloadsDelivered returns the payloads the relay delivered for the slot.
+	PayloadsDelivered(ctx context.Context, slot phase0.Slot) ([]types.BidTrace, error)
+}
+
+type relayClient struct {
+	url    string
+	client http.Client
+}
+
+// NewRelayClient creates a new RelayClient. Relay URLs may carry the relay pubkey as user info,
+// which is stripped from requests.
+func NewRelayClient(rawURL string) RelayClient {
+	if u, err := url.Parse(rawURL); err == nil {
+		u.User = nil
+
+		rawURL = u.String()
+	}
+
+	return &relayClient{
+		url: strings.TrimSuffix(rawURL, "/"),
+		client: http.Client{
+			Timeout: time.Second * 10,
+		},
+	}
+}
+
+func (c *relayClient) get(ctx context.Context, path string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+path, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	rsp, err := c.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	defer rsp.Body.Close()
+
+	data, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return rsp.StatusCode, nil, err
+	}
+
+	return rsp.StatusCode, data, nil
+}
+
+func (c *relayClient) Status(ctx context.Context) error {
+	status, _, err := c.get(ctx, "/eth/v1/builder/status")
+	if err != nil {
+		return err
+	}
+
+	if status != http.StatusOK {
+		return fmt.Errorf("status code: %d", status)
+	}
+
+	return nil
+}
+
+func (c *relayClient) ValidatorRegistered(ctx context.Context, pubkey phase0.BLSPubKey) (bool, error) {
+	status, _, err := c.get(ctx, fmt.Sprintf("/relay/v1/data/validator_registration?pubkey=%s", pubkey.String()))
+	if err != nil {
+		return false, err
+	}
+
+	switch status {
+	case http.StatusOK:
+		return true, nil
+	// Relays respond with 400 or 404 for validators without a registration.
+	case http.StatusBadRequest, http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("status code: %d", status)
+	}
+}
+
+func (c *relayClient) PayloadsDelivered(ctx context.Context, slot phase0.Slot) ([]types.BidTrace, error) {
+	status, data, err := c.get(ctx, fmt.Sprintf("/relay/v1/data/bidtraces/proposer_payload_delivered?slot=%d", slot))
+	if err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("status code: %d", status)
+	}
+
+	rsp := []types.BidTrace{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// SyncCommittee is the sync committee of a state.
+type SyncCommittee struct {
+	Validators []phase0.ValidatorIndex `json:"validators"`
+}
+
+// BlockHeader is a beacon block header.
+type BlockHeader struct {
+	Root      phase0.Root `json:"root"`
+	Canonical bool        `json:"canonical"`
+	Header    struct {
+		Message struct {
+			Slot          phase0.Slot           `json:"slot"`
+			ProposerIndex phase0.ValidatorIndex `json:"proposer_index"`
+			ParentRoot    phase0.Root           `json:"parent_root"`
+		} `json:"message"`
+	} `json:"header"`
+}
+
+// Checkpoint is a beacon chain checkpoint.
+type Checkpoint struct {
+	Epoch phase0.Epoch `json:"epoch"`
+	Root  phase0.Root  `json:"root"`
+}
+
+// FinalityCheckpoints are the finality checkpoints of a state.
+type FinalityCheckpoints struct {
+	PreviousJustified Checkpoint `json:"previous_justified"`
+	CurrentJustified  Checkpoint `json:"current_justified"`
+	Finalized         Checkpoint `json:"finalized"`
+}
@@ -0,0 +1,6 @@
+package types
+
+// DataColumnSidecar is a PeerDAS data column sidecar. Only the fields used by the exporter are decoded.
+type DataColumnSidecar struct {
+	Index string `json:"index"`
+}
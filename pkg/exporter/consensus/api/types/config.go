@@ -0,0 +1,17 @@
+package types
+
+import (
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// Fork is an entry of the fork schedule.
+type Fork struct {
+	PreviousVersion string       `json:"previous_version"`
+	CurrentVersion  string       `json:"current_version"`
+	Epoch           phase0.Epoch `json:"epoch"`
+}
+
+// Genesis is the genesis of the chain.
+type Genesis struct {
+	GenesisTime uint64 `json:"genesis_time,string"`
+}
@@ -0,0 +1,12 @@
+package types
+
+import (
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// SyncCommitteeDuty is the sync committee duty of a single validator in a sync committee period.
+type SyncCommitteeDuty struct {
+	Pubkey                        string                `json:"pubkey"`
+	ValidatorIndex                phase0.ValidatorIndex `json:"validator_index"`
+	ValidatorSyncCommitteeIndices []string              `json:"validator_sync_committee_indices"`
+}
@@ -0,0 +1,25 @@
+package types
+
+import (
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// LightClientHeader is the beacon header part of a light client header.
+type LightClientHeader struct {
+	Beacon struct {
+		Slot phase0.Slot `json:"slot"`
+	} `json:"beacon"`
+}
+
+// LightClientUpdate is a light client finality or optimistic update. FinalizedHeader is only
+// set for finality updates.
+type LightClientUpdate struct {
+	AttestedHeader  LightClientHeader  `json:"attested_header"`
+	FinalizedHeader *LightClientHeader `json:"finalized_header,omitempty"`
+	SignatureSlot   phase0.Slot        `json:"signature_slot"`
+}
+
+// LightClientBootstrap is the light client bootstrap for a block root.
+type LightClientBootstrap struct {
+	Header LightClientHeader `json:"header"`
+}
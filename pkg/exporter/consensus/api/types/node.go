@@ -0,0 +1,30 @@
+package types
+
+// Identity is the identity of the beacon node, including the Fulu metadata fields
+// that the beacon library does not expose.
+type Identity struct {
+	PeerID             string   `json:"peer_id"`
+	ENR                string   `json:"enr"`
+	P2PAddresses       []string `json:"p2p_addresses"`
+	DiscoveryAddresses []string `json:"discovery_addresses"`
+	Metadata           struct {
+		SeqNumber         string `json:"seq_number"`
+		Attnets           string `json:"attnets"`
+		Syncnets          string `json:"syncnets"`
+		CustodyGroupCount string `json:"custody_group_count"`
+	} `json:"metadata"`
+}
+
+// Peer is a peer of the beacon node. Agent is not part of the standard API but is returned by some clients.
+type Peer struct {
+	PeerID    string `json:"peer_id"`
+	State     string `json:"state"`
+	Direction string `json:"direction"`
+	Agent     string `json:"agent"`
+}
+
+// SyncStatus is the sync status of the beacon node. Only the fields used by the exporter are decoded.
+type SyncStatus struct {
+	IsOptimistic bool `json:"is_optimistic"`
+	ELOffline    bool `json:"el_offline"`
+}
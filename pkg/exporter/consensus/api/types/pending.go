@@ -0,0 +1,25 @@
+package types
+
+import (
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// PendingDeposit is a deposit waiting to be processed by the state (Electra and later).
+type PendingDeposit struct {
+	Pubkey string      `json:"pubkey"`
+	Amount phase0.Gwei `json:"amount"`
+	Slot   phase0.Slot `json:"slot"`
+}
+
+// PendingConsolidation is a consolidation waiting to be processed by the state (Electra and later).
+type PendingConsolidation struct {
+	SourceIndex phase0.ValidatorIndex `json:"source_index"`
+	TargetIndex phase0.ValidatorIndex `json:"target_index"`
+}
+
+// PendingPartialWithdrawal is a partial withdrawal waiting to be processed by the state (Electra and later).
+type PendingPartialWithdrawal struct {
+	ValidatorIndex    phase0.ValidatorIndex `json:"validator_index"`
+	Amount            phase0.Gwei           `json:"amount"`
+	WithdrawableEpoch phase0.Epoch          `json:"withdrawable_epoch"`
+}
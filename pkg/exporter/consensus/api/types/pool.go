@@ -0,0 +1,41 @@
+package types
+
+import (
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// ProposerSlashing is a proposer slashing in the operation pool.
+type ProposerSlashing struct {
+	SignedHeader1 struct {
+		Message struct {
+			Slot          phase0.Slot           `json:"slot"`
+			ProposerIndex phase0.ValidatorIndex `json:"proposer_index"`
+		} `json:"message"`
+	} `json:"signed_header_1"`
+}
+
+// IndexedAttestation is an attestation with the indices of its attesters.
+type IndexedAttestation struct {
+	AttestingIndices []phase0.ValidatorIndex `json:"attesting_indices"`
+}
+
+// AttesterSlashing is an attester slashing in the operation pool.
+type AttesterSlashing struct {
+	Attestation1 IndexedAttestation `json:"attestation_1"`
+	Attestation2 IndexedAttestation `json:"attestation_2"`
+}
+
+// SignedVoluntaryExit is a voluntary exit in the operation pool.
+type SignedVoluntaryExit struct {
+	Message struct {
+		Epoch          phase0.Epoch          `json:"epoch"`
+		ValidatorIndex phase0.ValidatorIndex `json:"validator_index"`
+	} `json:"message"`
+}
+
+// SignedBLSToExecutionChange is a BLS to execution credentials change in the operation pool.
+type SignedBLSToExecutionChange struct {
+	Message struct {
+		ValidatorIndex phase0.ValidatorIndex `json:"validator_index"`
+	} `json:"message"`
+}
@@ -0,0 +1,15 @@
+package types
+
+import (
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// BidTrace is a payload delivered by a relay, as returned by its data API.
+type BidTrace struct {
+	Slot                 phase0.Slot `json:"slot"`
+	BlockHash            string      `json:"block_hash"`
+	BuilderPubkey        string      `json:"builder_pubkey"`
+	ProposerPubkey       string      `json:"proposer_pubkey"`
+	ProposerFeeRecipient string      `json:"proposer_fee_recipient"`
+	Value                string      `json:"value"`
+}
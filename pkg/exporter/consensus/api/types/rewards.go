@@ -0,0 +1,27 @@
+package types
+
+import (
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// AttestationRewards are the attestation rewards of an epoch.
+type AttestationRewards struct {
+	TotalRewards []AttestationReward `json:"total_rewards"`
+}
+
+// AttestationReward is the attestation reward of a single validator, in gwei.
+// Negative values are penalties.
+type AttestationReward struct {
+	ValidatorIndex phase0.ValidatorIndex `json:"validator_index"`
+	Head           int64                 `json:"head,string"`
+	Target         int64                 `json:"target,string"`
+	Source         int64                 `json:"source,string"`
+	InclusionDelay int64                 `json:"inclusion_delay,string,omitempty"`
+	Inactivity     int64                 `json:"inactivity,string"`
+}
+
+// SyncCommitteeReward is the sync committee reward of a single validator in a block, in gwei.
+type SyncCommitteeReward struct {
+	ValidatorIndex phase0.ValidatorIndex `json:"validator_index"`
+	Reward         int64                 `json:"reward,string"`
+}
@@ -0,0 +1,374 @@
+package jobs
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	eth2spec "github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api/types"
+	"github.com/ethpandaops/ethwallclock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// BlobsConfig configures the blob and data column availability job.
+type BlobsConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// BlobsTopics are the event stream topics the blobs job depends on.
+var BlobsTopics = []string{"head"}
+
+// Blobs exposes blob and PeerDAS data column availability for new head blocks.
+type Blobs struct {
+	beacon beacon.Node
+	api    api.ConsensusClient
+	log    logrus.FieldLogger
+
+	mu                *sync.Mutex
+	lastRoot          phase0.Root
+	custodyGroupCount uint64
+
+	HeadBlobCount       prometheus.Gauge
+	BlobsTotal          prometheus.Counter
+	MaxBlobsPerBlock    prometheus.Gauge
+	Availability        prometheus.HistogramVec
+	Incomplete          prometheus.CounterVec
+	RetrievalFailures   prometheus.CounterVec
+	CustodyColumnsCount prometheus.Gauge
+}
+
+const (
+	NameBlobs = "blobs"
+
+	// blobRetrievalAttempts is how often sidecars are fetched for a block before it is considered incomplete.
+	blobRetrievalAttempts = 4
+	blobRetrievalInterval = time.Second
+
+	kindBlobs   = "blobs"
+	kindColumns = "columns"
+
+	defaultNumberOfColumns       = 128
+	defaultNumberOfCustodyGroups = 128
+)
+
+func (b *Blobs) Name() string {
+	return NameBlobs
+}
+
+// NewBlobs returns a new Blobs instance.
+func NewBlobs(beac beacon.Node, consensusAPI api.ConsensusClient, log logrus.FieldLogger, namespace string, constLabels map[string]string) Blobs {
+	constLabels["module"] = NameBlobs
+
+	namespace += "_blobs"
+
+	return Blobs{
+		beacon: beac,
+		api:    consensusAPI,
+		log:    log.WithField("module", NameBlobs),
+		mu:     &sync.Mutex{},
+		HeadBlobCount: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "head_count",
+				Help:        "The number of blobs in the most recent head block.",
+				ConstLabels: constLabels,
+			},
+		),
+		BlobsTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "total",
+				Help:        "The number of blobs in observed head blocks.",
+				ConstLabels: constLabels,
+			},
+		),
+		MaxBlobsPerBlock: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "max_per_block",
+				Help:        "The maximum number of blobs per block at the current epoch, following the blob schedule.",
+				ConstLabels: constLabels,
+			},
+		),
+		Availability: *prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   namespace,
+				Name:        "availability_seconds",
+				Help:        "The time from the start of the slot until all blob sidecars or custody data columns of a head block were retrievable (in seconds).",
+				ConstLabels: constLabels,
+				Buckets:     []float64{1, 2, 3, 4, 5, 6, 8, 10, 12, 16, 24},
+			},
+			[]string{
+				"kind",
+			},
+		),
+		Incomplete: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "incomplete_total",
+				Help:        "The number of head blocks whose blob sidecars or custody data columns were not all retrievable.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"kind",
+			},
+		),
+		RetrievalFailures: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "retrieval_failures_total",
+				Help:        "The number of failed blob sidecar or data column sidecar requests.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"kind",
+			},
+		),
+		CustodyColumnsCount: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "custody_columns_count",
+				Help:        "The number of data columns the node custodies.",
+				ConstLabels: constLabels,
+			},
+		),
+	}
+}
+
+// Start registers the job with the beacon node.
+func (b *Blobs) Start(ctx context.Context) {
+	b.beacon.OnHead(ctx, func(ctx context.Context, event *v1.HeadEvent) error {
+		go b.observeHead(ctx, event)
+
+		return nil
+	})
+
+	b.beacon.OnReady(ctx, func(ctx context.Context, event *beacon.ReadyEvent) error {
+		b.beacon.Wallclock().OnEpochChanged(func(epoch ethwallclock.Epoch) {
+			b.updateNodeState(ctx, phase0.Epoch(epoch.Number()))
+		})
+
+		epoch := b.beacon.Wallclock().Epochs().Current()
+
+		b.updateNodeState(ctx, phase0.Epoch(epoch.Number()))
+
+		return nil
+	})
+}
+
+func (b *Blobs) updateNodeState(ctx context.Context, epoch phase0.Epoch) {
+	spec, err := b.beacon.Spec()
+	if err != nil {
+		b.log.WithError(err).Error("Failed to get spec")
+
+		return
+	}
+
+	b.MaxBlobsPerBlock.Set(float64(maxBlobsPerBlock(spec, epoch)))
+}
+
+// ObserveIdentity updates the custody of the node from its identity, which is fetched by the
+// identity job.
+func (b *Blobs) ObserveIdentity(identity *types.Identity) {
+	// Nodes before Fulu do not advertise a custody group count.
+	if identity.Metadata.CustodyGroupCount == "" {
+		return
+	}
+
+	count, err := strconv.ParseUint(identity.Metadata.CustodyGroupCount, 10, 64)
+	if err != nil {
+		b.log.WithError(err).Error("Failed to parse custody group count")
+
+		return
+	}
+
+	spec, err := b.beacon.Spec()
+	if err != nil {
+		b.log.WithError(err).Error("Failed to get spec")
+
+		return
+	}
+
+	b.mu.Lock()
+	b.custodyGroupCount = count
+	b.mu.Unlock()
+
+	b.CustodyColumnsCount.Set(float64(custodyColumns(spec, count)))
+}
+
+// maxBlobsPerBlock returns the blob limit at the epoch, using the blob schedule when the
+// spec has one and falling back to the Electra and Deneb limits otherwise.
+func maxBlobsPerBlock(spec *state.Spec, epoch phase0.Epoch) uint64 {
+	if limit := spec.GetMaxBlobsPerBlock(epoch); limit > 0 {
+		return limit
+	}
+
+	if electra, err := spec.ForkEpochs.GetByName("electra"); err == nil && electra.Active(epoch) {
+		if limit := specUint(spec, "MAX_BLOBS_PER_BLOCK_ELECTRA", 0); limit > 0 {
+			return limit
+		}
+	}
+
+	return specUint(spec, "MAX_BLOBS_PER_BLOCK", 0)
+}
+
+func custodyColumns(spec *state.Spec, custodyGroupCount uint64) uint64 {
+	columns := specUint(spec, "NUMBER_OF_COLUMNS", defaultNumberOfColumns)
+	groups := specUint(spec, "NUMBER_OF_CUSTODY_GROUPS", defaultNumberOfCustodyGroups)
+
+	if groups == 0 {
+		return columns
+	}
+
+	custody := custodyGroupCount * (columns / groups)
+	if custody > columns {
+		return columns
+	}
+
+	return custody
+}
+
+// specUint reads an integer value from the full spec.
+func specUint(spec *state.Spec, key string, fallback uint64) uint64 {
+	raw, ok := spec.FullSpec[key]
+	if !ok {
+		return fallback
+	}
+
+	switch v := raw.(type) {
+	case string:
+		value, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fallback
+		}
+
+		return value
+	case float64:
+		return uint64(v)
+	case uint64:
+		return v
+	default:
+		return fallback
+	}
+}
+
+func (b *Blobs) observeHead(ctx context.Context, event *v1.HeadEvent) {
+	b.mu.Lock()
+
+	if b.lastRoot == event.Block {
+		b.mu.Unlock()
+
+		return
+	}
+
+	b.lastRoot = event.Block
+	custodyGroupCount := b.custodyGroupCount
+
+	b.mu.Unlock()
+
+	root := event.Block.String()
+
+	block, err := b.beacon.FetchBlock(ctx, root)
+	if err != nil {
+		b.log.WithError(err).WithField("block", root).Warn("Failed to fetch head block")
+
+		return
+	}
+
+	// Blocks before Deneb do not carry blobs.
+	if block.Version < eth2spec.DataVersionDeneb {
+		return
+	}
+
+	commitments, err := block.BlobKZGCommitments()
+	if err != nil {
+		b.log.WithError(err).WithField("block", root).Warn("Failed to get blob commitments")
+
+		return
+	}
+
+	b.HeadBlobCount.Set(float64(len(commitments)))
+	b.BlobsTotal.Add(float64(len(commitments)))
+
+	if len(commitments) == 0 {
+		return
+	}
+
+	spec, err := b.beacon.Spec()
+	if err != nil {
+		return
+	}
+
+	genesis, err := b.beacon.Genesis()
+	if err != nil {
+		return
+	}
+
+	slotStart := genesis.GenesisTime.Add(time.Duration(spec.SecondsPerSlot) * time.Duration(event.Slot))
+
+	kind := kindBlobs
+	expected := len(commitments)
+
+	if block.Version >= eth2spec.DataVersionFulu {
+		kind = kindColumns
+		expected = int(custodyColumns(spec, custodyGroupCount))
+	}
+
+	// The custody group count is not known until the identity job has fetched the node identity.
+	if expected == 0 {
+		return
+	}
+
+	for attempt := 0; attempt < blobRetrievalAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(blobRetrievalInterval):
+			}
+		}
+
+		available, err := b.availableSidecars(ctx, kind, root)
+		if err != nil {
+			b.RetrievalFailures.WithLabelValues(kind).Inc()
+
+			b.log.WithError(err).WithField("block", root).WithField("kind", kind).Debug("Failed to fetch sidecars")
+
+			continue
+		}
+
+		if available >= expected {
+			b.Availability.WithLabelValues(kind).Observe(time.Since(slotStart).Seconds())
+
+			return
+		}
+	}
+
+	b.Incomplete.WithLabelValues(kind).Inc()
+}
+
+func (b *Blobs) availableSidecars(ctx context.Context, kind, root string) (int, error) {
+	if kind == kindColumns {
+		columns, err := b.api.DataColumnSidecars(ctx, root)
+		if err != nil {
+			return 0, err
+		}
+
+		return len(columns), nil
+	}
+
+	sidecars, err := b.beacon.FetchBeaconBlockBlobs(ctx, root)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(sidecars), nil
+}
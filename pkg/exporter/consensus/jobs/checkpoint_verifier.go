@@ -0,0 +1,238 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api"
+	"github.com/ethpandaops/ethwallclock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// CheckpointVerifier cross-checks the finalized checkpoint of the beacon node against other
+// trusted beacon nodes to detect nodes stuck on a minority fork.
+type CheckpointVerifier struct {
+	beacon    beacon.Node
+	api       api.ConsensusClient
+	verifiers map[string]api.ConsensusClient
+	log       logrus.FieldLogger
+
+	mu *sync.Mutex
+	// diverged holds the last finalized epoch a divergence was counted for, per verifier.
+	diverged map[string]phase0.Epoch
+
+	Agreement      prometheus.GaugeVec
+	EpochLag       prometheus.GaugeVec
+	Divergences    prometheus.CounterVec
+	FinalizedEpoch prometheus.GaugeVec
+	Errors         prometheus.CounterVec
+}
+
+const (
+	NameCheckpointVerifier = "checkpoint_verifier"
+)
+
+func (c *CheckpointVerifier) Name() string {
+	return NameCheckpointVerifier
+}
+
+// NewCheckpointVerifier returns a new CheckpointVerifier instance. Verifiers are keyed by the
+// name used in the verifier label.
+func NewCheckpointVerifier(beac beacon.Node, consensusAPI api.ConsensusClient, log logrus.FieldLogger, namespace string, constLabels map[string]string, verifiers map[string]api.ConsensusClient) CheckpointVerifier {
+	constLabels["module"] = NameCheckpointVerifier
+
+	namespace += "_checkpoint_verifier"
+
+	return CheckpointVerifier{
+		beacon:    beac,
+		api:       consensusAPI,
+		verifiers: verifiers,
+		log:       log.WithField("module", NameCheckpointVerifier),
+		mu:        &sync.Mutex{},
+		diverged:  make(map[string]phase0.Epoch),
+		Agreement: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "agreement",
+				Help:        "Whether the verifier agrees with the finalized checkpoint of the node (1 for agreement). When the finalized epochs differ, the checkpoint roots at the lower epoch are compared.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"verifier",
+			},
+		),
+		EpochLag: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "epoch_lag",
+				Help:        "The finalized epoch of the verifier minus the finalized epoch of the node. Positive values mean the node lags behind.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"verifier",
+			},
+		),
+		Divergences: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "divergences_total",
+				Help:        "The number of finalized epochs for which the verifier reported a different checkpoint root than the node.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"verifier",
+			},
+		),
+		FinalizedEpoch: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "finalized_epoch",
+				Help:        "The finalized epoch reported by the verifier.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"verifier",
+			},
+		),
+		Errors: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "errors_total",
+				Help:        "The number of failed requests to the verifier.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"verifier",
+			},
+		),
+	}
+}
+
+// Enabled returns true if any verifiers are configured.
+func (c *CheckpointVerifier) Enabled() bool {
+	return len(c.verifiers) > 0
+}
+
+// Start registers the job with the beacon node.
+func (c *CheckpointVerifier) Start(ctx context.Context) {
+	c.beacon.OnReady(ctx, func(ctx context.Context, event *beacon.ReadyEvent) error {
+		c.beacon.Wallclock().OnEpochChanged(func(epoch ethwallclock.Epoch) {
+			c.tick(ctx)
+		})
+
+		c.tick(ctx)
+
+		return nil
+	})
+}
+
+func (c *CheckpointVerifier) tick(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	local, err := c.api.FinalityCheckpoints(ctx, "finalized")
+	if err != nil {
+		c.log.WithError(err).Error("Failed to get finality checkpoints")
+
+		return
+	}
+
+	for name, verifier := range c.verifiers {
+		remote, err := verifier.FinalityCheckpoints(ctx, "finalized")
+		if err != nil {
+			c.Errors.WithLabelValues(name).Inc()
+
+			c.log.WithError(err).WithField("verifier", name).Warn("Failed to get finality checkpoints from verifier")
+
+			continue
+		}
+
+		c.FinalizedEpoch.WithLabelValues(name).Set(float64(remote.Finalized.Epoch))
+		c.EpochLag.WithLabelValues(name).Set(float64(remote.Finalized.Epoch) - float64(local.Finalized.Epoch))
+
+		// When one side finalized further, compare the root it has at the epoch the other side
+		// finalized, so that agreement reflects the current checkpoints.
+		epoch := local.Finalized.Epoch
+		localRoot := local.Finalized.Root
+		remoteRoot := remote.Finalized.Root
+
+		switch {
+		case remote.Finalized.Epoch > local.Finalized.Epoch:
+			remoteRoot, err = c.checkpointRoot(ctx, verifier, epoch)
+			if err != nil {
+				c.Errors.WithLabelValues(name).Inc()
+			}
+		case remote.Finalized.Epoch < local.Finalized.Epoch:
+			epoch = remote.Finalized.Epoch
+			localRoot, err = c.checkpointRoot(ctx, c.api, epoch)
+		}
+
+		if err != nil {
+			// Agreement is unknown rather than stale when the roots could not be compared.
+			c.Agreement.DeleteLabelValues(name)
+
+			c.log.WithError(err).WithField("verifier", name).WithField("epoch", epoch).Warn("Failed to get checkpoint root")
+
+			continue
+		}
+
+		c.compare(name, epoch, localRoot, remoteRoot)
+	}
+}
+
+// checkpointRoot returns the checkpoint root of the epoch, which is the root of the latest block
+// at or before the first slot of the epoch.
+func (c *CheckpointVerifier) checkpointRoot(ctx context.Context, client api.ConsensusClient, epoch phase0.Epoch) (phase0.Root, error) {
+	spec, err := c.beacon.Spec()
+	if err != nil {
+		return phase0.Root{}, err
+	}
+
+	start := uint64(epoch) * uint64(spec.SlotsPerEpoch)
+
+	for slot := start; ; slot-- {
+		header, err := client.BlockHeader(ctx, fmt.Sprintf("%d", slot))
+		if err != nil && !errors.Is(err, api.ErrNotFound) {
+			return phase0.Root{}, err
+		}
+
+		if header != nil && uint64(header.Header.Message.Slot) == slot {
+			return header.Root, nil
+		}
+
+		if slot == 0 || start-slot >= uint64(spec.SlotsPerEpoch) {
+			return phase0.Root{}, fmt.Errorf("no block found at or before slot %d", start)
+		}
+	}
+}
+
+// compare updates the agreement of a verifier from the checkpoint roots of both nodes at the
+// same epoch.
+func (c *CheckpointVerifier) compare(name string, epoch phase0.Epoch, local, remote phase0.Root) {
+	if local == remote {
+		c.Agreement.WithLabelValues(name).Set(1)
+
+		return
+	}
+
+	c.Agreement.WithLabelValues(name).Set(0)
+
+	if last, ok := c.diverged[name]; ok && last == epoch {
+		return
+	}
+
+	c.diverged[name] = epoch
+	c.Divergences.WithLabelValues(name).Inc()
+
+	c.log.
+		WithField("verifier", name).
+		WithField("epoch", epoch).
+		WithField("root", local.String()).
+		WithField("verifier_root", remote.String()).
+		Error("Finalized checkpoint diverges from verifier")
+}
@@ -0,0 +1,249 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api"
+	"github.com/ethpandaops/ethwallclock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// Duties exposes the upcoming proposer and sync committee duties of the watched validators.
+type Duties struct {
+	beacon beacon.Node
+	api    api.ConsensusClient
+	log    logrus.FieldLogger
+
+	watched *WatchedValidators
+
+	mu            *sync.Mutex
+	nextProposals map[phase0.ValidatorIndex]phase0.Slot
+
+	NextProposalSlot           prometheus.GaugeVec
+	NextProposalSeconds        prometheus.GaugeVec
+	CurrentSyncCommitteeMember prometheus.GaugeVec
+	NextSyncCommitteeMember    prometheus.GaugeVec
+}
+
+const (
+	NameDuties = "duties"
+)
+
+func (d *Duties) Name() string {
+	return NameDuties
+}
+
+// Enabled returns true if there is at least one validator to watch.
+func (d *Duties) Enabled() bool {
+	return !d.watched.Empty()
+}
+
+// NewDuties returns a new Duties instance.
+func NewDuties(beac beacon.Node, consensusAPI api.ConsensusClient, log logrus.FieldLogger, namespace string, constLabels map[string]string, watched *WatchedValidators) Duties {
+	constLabels["module"] = NameDuties
+
+	namespace += "_validator"
+
+	log = log.WithField("module", NameDuties)
+
+	return Duties{
+		beacon:        beac,
+		api:           consensusAPI,
+		log:           log,
+		watched:       watched,
+		mu:            &sync.Mutex{},
+		nextProposals: make(map[phase0.ValidatorIndex]phase0.Slot),
+		NextProposalSlot: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "next_proposal_slot",
+				Help:        "The slot of the next known block proposal of the validator.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"index",
+				"name",
+			},
+		),
+		NextProposalSeconds: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "next_proposal_seconds",
+				Help:        "The number of seconds until the next known block proposal of the validator.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"index",
+				"name",
+			},
+		),
+		CurrentSyncCommitteeMember: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "current_sync_committee_member",
+				Help:        "Whether the validator is a member of the current sync committee (1 for member).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"index",
+				"name",
+			},
+		),
+		NextSyncCommitteeMember: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "next_sync_committee_member",
+				Help:        "Whether the validator is a member of the next sync committee (1 for member).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"index",
+				"name",
+			},
+		),
+	}
+}
+
+// Start registers the job with the beacon node. Duties are fetched on every new epoch and
+// the proposal countdown is updated on every new slot.
+func (d *Duties) Start(ctx context.Context) {
+	d.beacon.OnReady(ctx, func(ctx context.Context, event *beacon.ReadyEvent) error {
+		d.beacon.Wallclock().OnEpochChanged(func(epoch ethwallclock.Epoch) {
+			d.tick(ctx, phase0.Epoch(epoch.Number()))
+		})
+
+		d.beacon.Wallclock().OnSlotChanged(func(slot ethwallclock.Slot) {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+
+			d.updateCountdown(phase0.Slot(slot.Number()))
+		})
+
+		epoch := d.beacon.Wallclock().Epochs().Current()
+
+		d.tick(ctx, phase0.Epoch(epoch.Number()))
+
+		return nil
+	})
+}
+
+func (d *Duties) tick(ctx context.Context, current phase0.Epoch) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, err := d.watched.Resolve(ctx, d.beacon); err != nil {
+		d.log.WithError(err).Error("Failed to resolve validators")
+
+		return
+	}
+
+	if err := d.observeProposerDuties(ctx, current); err != nil {
+		d.log.WithError(err).WithField("epoch", current).Error("Failed to observe proposer duties")
+	}
+
+	if err := d.observeSyncCommitteeDuties(ctx, current); err != nil {
+		d.log.WithError(err).WithField("epoch", current).Error("Failed to observe sync committee duties")
+	}
+}
+
+func (d *Duties) observeProposerDuties(ctx context.Context, current phase0.Epoch) error {
+	duties, err := d.beacon.FetchProposerDuties(ctx, current)
+	if err != nil {
+		return err
+	}
+
+	// Duties for the next epoch are not final and not every client serves them, so a failure is not fatal.
+	next, err := d.beacon.FetchProposerDuties(ctx, current+1)
+	if err != nil {
+		d.log.WithError(err).WithField("epoch", current+1).Debug("Failed to fetch proposer duties for the next epoch")
+	} else {
+		duties = append(duties, next...)
+	}
+
+	slot := d.beacon.Wallclock().Slots().Current()
+	currentSlot := phase0.Slot(slot.Number())
+
+	nextProposals := make(map[phase0.ValidatorIndex]phase0.Slot)
+
+	for _, duty := range duties {
+		if !d.watched.Contains(duty.ValidatorIndex) || duty.Slot < currentSlot {
+			continue
+		}
+
+		if existing, ok := nextProposals[duty.ValidatorIndex]; !ok || duty.Slot < existing {
+			nextProposals[duty.ValidatorIndex] = duty.Slot
+		}
+	}
+
+	d.nextProposals = nextProposals
+
+	d.updateCountdown(currentSlot)
+
+	return nil
+}
+
+func (d *Duties) updateCountdown(current phase0.Slot) {
+	for _, index := range d.watched.Indices() {
+		indexLabel, name := d.watched.Labels(index)
+
+		slot, ok := d.nextProposals[index]
+		if !ok || slot < current {
+			d.NextProposalSlot.DeletePartialMatch(prometheus.Labels{"index": indexLabel})
+			d.NextProposalSeconds.DeletePartialMatch(prometheus.Labels{"index": indexLabel})
+
+			continue
+		}
+
+		s := d.beacon.Wallclock().Slots().FromNumber(uint64(slot))
+
+		d.NextProposalSlot.WithLabelValues(indexLabel, name).Set(float64(slot))
+		d.NextProposalSeconds.WithLabelValues(indexLabel, name).Set(s.TimeWindow().StartsIn().Seconds())
+	}
+}
+
+func (d *Duties) observeSyncCommitteeDuties(ctx context.Context, current phase0.Epoch) error {
+	indices := d.watched.Indices()
+	if len(indices) == 0 {
+		return nil
+	}
+
+	spec, err := d.beacon.Spec()
+	if err != nil {
+		return err
+	}
+
+	for _, period := range []struct {
+		epoch phase0.Epoch
+		gauge *prometheus.GaugeVec
+	}{
+		{epoch: current, gauge: &d.CurrentSyncCommitteeMember},
+		{epoch: current + spec.EpochsPerSyncCommitteePeriod, gauge: &d.NextSyncCommitteeMember},
+	} {
+		duties, err := d.api.SyncCommitteeDuties(ctx, period.epoch, indices)
+		if err != nil {
+			return err
+		}
+
+		members := make(map[phase0.ValidatorIndex]bool)
+		for _, duty := range duties {
+			members[duty.ValidatorIndex] = true
+		}
+
+		for _, index := range indices {
+			indexLabel, name := d.watched.Labels(index)
+
+			value := 0.0
+			if members[index] {
+				value = 1
+			}
+
+			period.gauge.WithLabelValues(indexLabel, name).Set(value)
+		}
+	}
+
+	return nil
+}
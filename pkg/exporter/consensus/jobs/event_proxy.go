@@ -0,0 +1,233 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// EventProxyConfig configures the server-sent events proxy of the beacon event stream.
+type EventProxyConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// BufferSize is the number of events buffered per subscriber before events are dropped.
+	BufferSize int `yaml:"bufferSize"`
+}
+
+// EventProxy re-exposes the beacon event stream to HTTP clients as server-sent events, so that
+// clients do not each need their own connection to the beacon node.
+type EventProxy struct {
+	beacon     beacon.Node
+	log        logrus.FieldLogger
+	topics     []string
+	bufferSize int
+
+	mu          *sync.RWMutex
+	subscribers map[*eventSubscriber]struct{}
+
+	Subscribers prometheus.Gauge
+	Dropped     prometheus.CounterVec
+}
+
+type eventSubscriber struct {
+	topics map[string]bool
+	events chan proxiedEvent
+}
+
+type proxiedEvent struct {
+	topic string
+	data  []byte
+}
+
+const (
+	NameEventProxy = "event_proxy"
+
+	defaultEventProxyBufferSize = 64
+	eventProxyKeepAlive         = 15 * time.Second
+)
+
+func (e *EventProxy) Name() string {
+	return NameEventProxy
+}
+
+// NewEventProxy returns a new EventProxy instance. Topics are the topics the beacon event stream
+// is subscribed to.
+func NewEventProxy(beac beacon.Node, log logrus.FieldLogger, namespace string, constLabels map[string]string, config EventProxyConfig, topics []string) EventProxy {
+	constLabels["module"] = NameEventProxy
+
+	namespace += "_event_proxy"
+
+	bufferSize := config.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultEventProxyBufferSize
+	}
+
+	return EventProxy{
+		beacon:      beac,
+		log:         log.WithField("module", NameEventProxy),
+		topics:      topics,
+		bufferSize:  bufferSize,
+		mu:          &sync.RWMutex{},
+		subscribers: make(map[*eventSubscriber]struct{}),
+		Subscribers: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "subscribers",
+				Help:        "The number of clients connected to the event stream proxy.",
+				ConstLabels: constLabels,
+			},
+		),
+		Dropped: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "dropped_events_total",
+				Help:        "The number of events dropped because a subscriber's buffer was full.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"topic",
+			},
+		),
+	}
+}
+
+// Start registers the job with the beacon node.
+func (e *EventProxy) Start(ctx context.Context) {
+	e.beacon.OnEvent(ctx, e.handleEvent)
+}
+
+func (e *EventProxy) handleEvent(ctx context.Context, event *v1.Event) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var data []byte
+
+	for subscriber := range e.subscribers {
+		if !subscriber.topics[event.Topic] {
+			continue
+		}
+
+		// Only marshal events that at least one subscriber is interested in.
+		if data == nil {
+			raw, err := json.Marshal(event.Data)
+			if err != nil {
+				return fmt.Errorf("failed to marshal %s event: %w", event.Topic, err)
+			}
+
+			data = raw
+		}
+
+		select {
+		case subscriber.events <- proxiedEvent{topic: event.Topic, data: data}:
+		default:
+			e.Dropped.WithLabelValues(event.Topic).Inc()
+		}
+	}
+
+	return nil
+}
+
+// ServeHTTP streams events to the client. Topics are selected with the topics query parameter,
+// either comma separated or repeated, and default to every subscribed topic.
+func (e *EventProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+
+		return
+	}
+
+	topics, err := e.parseTopics(r.URL.Query()["topics"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	subscriber := &eventSubscriber{
+		topics: topics,
+		events: make(chan proxiedEvent, e.bufferSize),
+	}
+
+	e.subscribe(subscriber)
+	defer e.unsubscribe(subscriber)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventProxyKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case event := <-subscriber.events:
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.topic, event.data); err != nil {
+				return
+			}
+		}
+
+		flusher.Flush()
+	}
+}
+
+func (e *EventProxy) parseTopics(values []string) (map[string]bool, error) {
+	topics := make(map[string]bool)
+
+	for _, value := range values {
+		for _, topic := range strings.Split(value, ",") {
+			topic = strings.TrimSpace(topic)
+			if topic == "" {
+				continue
+			}
+
+			if !beacon.EventTopics(e.topics).Exists(topic) {
+				return nil, fmt.Errorf("topic %q is not subscribed, available topics: %s", topic, strings.Join(e.topics, ", "))
+			}
+
+			topics[topic] = true
+		}
+	}
+
+	if len(topics) == 0 {
+		for _, topic := range e.topics {
+			topics[topic] = true
+		}
+	}
+
+	return topics, nil
+}
+
+func (e *EventProxy) subscribe(subscriber *eventSubscriber) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.subscribers[subscriber] = struct{}{}
+
+	e.Subscribers.Set(float64(len(e.subscribers)))
+}
+
+func (e *EventProxy) unsubscribe(subscriber *eventSubscriber) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.subscribers, subscriber)
+
+	e.Subscribers.Set(float64(len(e.subscribers)))
+}
@@ -0,0 +1,262 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/ethpandaops/ethwallclock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// EventTopic describes a beacon API event stream topic.
+type EventTopic struct {
+	// Fork is the fork the topic was introduced in.
+	Fork string
+	// Periodic is true for topics that are expected to fire at least every epoch on a healthy network.
+	// Topics that depend on the node, e.g. payload_attributes which some nodes only publish while
+	// they have proposers to prepare for, or on finality, are not periodic.
+	Periodic bool
+}
+
+// KnownEventTopics are the beacon API event stream topics supported by the event stream client.
+// The beacon library only decodes some of them itself and logs an error for the others, which the
+// exporter filters out as the jobs receive every event through OnEvent.
+var KnownEventTopics = map[string]EventTopic{
+	"head":                    {Fork: "phase0", Periodic: true},
+	"block":                   {Fork: "phase0", Periodic: true},
+	"block_gossip":            {Fork: "phase0", Periodic: true},
+	"attestation":             {Fork: "phase0"},
+	"voluntary_exit":          {Fork: "phase0"},
+	"proposer_slashing":       {Fork: "phase0"},
+	"attester_slashing":       {Fork: "phase0"},
+	"finalized_checkpoint":    {Fork: "phase0"},
+	"chain_reorg":             {Fork: "phase0"},
+	"contribution_and_proof":  {Fork: "altair", Periodic: true},
+	"payload_attributes":      {Fork: "bellatrix"},
+	"bls_to_execution_change": {Fork: "capella"},
+	"blob_sidecar":            {Fork: "deneb"},
+	"single_attestation":      {Fork: "electra", Periodic: true},
+	"data_column_sidecar":     {Fork: "fulu"},
+}
+
+// ValidateEventTopics returns an error listing every topic that is not a known beacon API topic.
+func ValidateEventTopics(topics []string) error {
+	unknown := []string{}
+
+	for _, topic := range topics {
+		if _, ok := KnownEventTopics[topic]; !ok {
+			unknown = append(unknown, topic)
+		}
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	known := make([]string, 0, len(KnownEventTopics))
+	for topic := range KnownEventTopics {
+		known = append(known, topic)
+	}
+
+	sort.Strings(known)
+
+	return fmt.Errorf("unknown event stream topics: %s (known topics: %s)", strings.Join(unknown, ", "), strings.Join(known, ", "))
+}
+
+// ValidateEventTopicForks returns an error listing every topic whose fork is not active yet, as
+// reported by active.
+func ValidateEventTopicForks(topics []string, active func(fork string) bool) error {
+	inactive := []string{}
+
+	for _, topic := range topics {
+		known, ok := KnownEventTopics[topic]
+		if !ok {
+			continue
+		}
+
+		if !active(known.Fork) {
+			inactive = append(inactive, fmt.Sprintf("%s (%s)", topic, known.Fork))
+		}
+	}
+
+	if len(inactive) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("event stream topics of forks that are not active yet: %s", strings.Join(inactive, ", "))
+}
+
+// EventStream detects periodic topics that went silent, so that a broken subscription becomes
+// visible. The events received per topic are already counted by the beacon library.
+type EventStream struct {
+	beacon beacon.Node
+	log    logrus.FieldLogger
+
+	topics       []string
+	silentEpochs uint64
+
+	mu      *sync.Mutex
+	started time.Time
+	last    map[string]time.Time
+	silent  map[string]bool
+
+	SinceLastEvent prometheus.GaugeVec
+	Silent         prometheus.GaugeVec
+	ForkInactive   prometheus.GaugeVec
+}
+
+const (
+	NameEventStream = "event_stream"
+
+	defaultSilentEpochs = 2
+)
+
+func (e *EventStream) Name() string {
+	return NameEventStream
+}
+
+// NewEventStream returns a new EventStream instance for the subscribed topics. Periodic topics
+// are reported as silent after silentEpochs epochs without an event.
+func NewEventStream(beac beacon.Node, log logrus.FieldLogger, namespace string, constLabels map[string]string, topics []string, silentEpochs uint64) EventStream {
+	constLabels["module"] = NameEventStream
+
+	namespace += "_event_stream"
+
+	if silentEpochs == 0 {
+		silentEpochs = defaultSilentEpochs
+	}
+
+	return EventStream{
+		beacon:       beac,
+		log:          log.WithField("module", NameEventStream),
+		topics:       topics,
+		silentEpochs: silentEpochs,
+		mu:           &sync.Mutex{},
+		last:         make(map[string]time.Time),
+		silent:       make(map[string]bool),
+		SinceLastEvent: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "seconds_since_last_event",
+				Help:        "The number of seconds since the last event of the topic was received, or since the subscription started.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"topic",
+			},
+		),
+		Silent: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "topic_silent",
+				Help:        "Whether a topic that is expected to fire every epoch has been silent for too long (1 for silent).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"topic",
+			},
+		),
+		ForkInactive: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "topic_fork_inactive",
+				Help:        "Whether a subscribed topic belongs to a fork that is not active on the network yet (1 for inactive).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"topic",
+			},
+		),
+	}
+}
+
+// Start registers the job with the beacon node.
+func (e *EventStream) Start(ctx context.Context) {
+	e.started = time.Now()
+
+	e.beacon.OnEvent(ctx, e.handleEvent)
+
+	e.beacon.OnReady(ctx, func(ctx context.Context, event *beacon.ReadyEvent) error {
+		e.beacon.Wallclock().OnSlotChanged(func(slot ethwallclock.Slot) {
+			e.check()
+		})
+
+		e.check()
+
+		return nil
+	})
+}
+
+func (e *EventStream) handleEvent(ctx context.Context, event *v1.Event) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.last[event.Topic] = time.Now()
+
+	return nil
+}
+
+func (e *EventStream) check() {
+	spec, err := e.beacon.Spec()
+	if err != nil {
+		return
+	}
+
+	epoch := e.beacon.Wallclock().Epochs().Current()
+	current := phase0.Epoch(epoch.Number())
+
+	threshold := time.Duration(e.silentEpochs) * time.Duration(spec.SlotsPerEpoch) * time.Duration(spec.SecondsPerSlot)
+
+	// Nodes do not publish most events while syncing.
+	syncing := e.beacon.Status().Syncing()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, topic := range e.topics {
+		last, ok := e.last[topic]
+		if !ok {
+			last = e.started
+		}
+
+		since := time.Since(last)
+
+		e.SinceLastEvent.WithLabelValues(topic).Set(since.Seconds())
+
+		known := KnownEventTopics[topic]
+
+		active := true
+
+		if fork, err := spec.ForkEpochs.GetByName(known.Fork); err == nil {
+			active = fork.Active(current)
+		}
+
+		if active {
+			e.ForkInactive.WithLabelValues(topic).Set(0)
+		} else {
+			e.ForkInactive.WithLabelValues(topic).Set(1)
+		}
+
+		silent := known.Periodic && active && !syncing && since > threshold
+
+		if silent && !e.silent[topic] {
+			e.log.WithField("topic", topic).WithField("since", since.String()).Warn("Event stream topic has gone silent")
+		}
+
+		e.silent[topic] = silent
+
+		if silent {
+			e.Silent.WithLabelValues(topic).Set(1)
+		} else {
+			e.Silent.WithLabelValues(topic).Set(0)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package jobs
+
+import (
+	"testing"
+)
+
+func TestValidateEventTopics(t *testing.T) {
+	tests := []struct {
+		name    string
+		topics  []string
+		wantErr bool
+	}{
+		{
+			name:   "no topics",
+			topics: []string{},
+		},
+		{
+			name:   "known topics across forks",
+			topics: []string{"head", "payload_attributes", "blob_sidecar", "single_attestation", "data_column_sidecar"},
+		},
+		{
+			name:    "typo in topic",
+			topics:  []string{"head", "finalised_checkpoint"},
+			wantErr: true,
+		},
+		{
+			name:    "topic not supported by the event stream client",
+			topics:  []string{"light_client_finality_update"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateEventTopics(tt.topics)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateEventTopics() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateEventTopicForks(t *testing.T) {
+	activeUntil := func(last string) func(fork string) bool {
+		forks := []string{"phase0", "altair", "bellatrix", "capella", "deneb", "electra", "fulu"}
+
+		return func(fork string) bool {
+			for _, f := range forks {
+				if f == fork {
+					return true
+				}
+
+				if f == last {
+					return false
+				}
+			}
+
+			return false
+		}
+	}
+
+	tests := []struct {
+		name    string
+		topics  []string
+		last    string
+		wantErr bool
+	}{
+		{
+			name:   "phase0 topics on phase0",
+			topics: []string{"head", "block", "proposer_slashing"},
+			last:   "phase0",
+		},
+		{
+			name:   "all topics on fulu",
+			topics: []string{"head", "payload_attributes", "blob_sidecar", "single_attestation", "data_column_sidecar"},
+			last:   "fulu",
+		},
+		{
+			name:    "data_column_sidecar before fulu",
+			topics:  []string{"head", "blob_sidecar", "data_column_sidecar"},
+			last:    "electra",
+			wantErr: true,
+		},
+		{
+			name:    "single_attestation before electra",
+			topics:  []string{"single_attestation"},
+			last:    "deneb",
+			wantErr: true,
+		},
+		{
+			name:   "unknown topics are left to ValidateEventTopics",
+			topics: []string{"finalised_checkpoint"},
+			last:   "phase0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateEventTopicForks(tt.topics, activeUntil(tt.last))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateEventTopicForks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// EventTiming observes when beacon events arrive relative to the start of their slot.
+type EventTiming struct {
+	beacon beacon.Node
+	log    logrus.FieldLogger
+
+	Arrival prometheus.HistogramVec
+}
+
+const (
+	NameEventTiming = "event_timing"
+)
+
+// EventTimingTopics are the event stream topics that arrival times are observed for.
+var EventTimingTopics = []string{"block", "head", "blob_sidecar", "data_column_sidecar", "single_attestation", "payload_attributes"}
+
+func (e *EventTiming) Name() string {
+	return NameEventTiming
+}
+
+// NewEventTiming returns a new EventTiming instance.
+func NewEventTiming(beac beacon.Node, log logrus.FieldLogger, namespace string, constLabels map[string]string) EventTiming {
+	constLabels["module"] = NameEventTiming
+
+	namespace += "_event"
+
+	return EventTiming{
+		beacon: beac,
+		log:    log.WithField("module", NameEventTiming),
+		Arrival: *prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   namespace,
+				Name:        "arrival_seconds",
+				Help:        "The arrival time of beacon events relative to the start of their slot (in seconds). payload_attributes events are relative to the proposal slot and are usually negative.",
+				ConstLabels: constLabels,
+				Buckets:     []float64{-8, -4, -2, -1, 0, 0.25, 0.5, 1, 1.5, 2, 2.5, 3, 4, 6, 8, 12},
+			},
+			[]string{
+				"topic",
+			},
+		),
+	}
+}
+
+// Start registers the job with the beacon node.
+func (e *EventTiming) Start(ctx context.Context) {
+	e.beacon.OnEvent(ctx, e.handleEvent)
+}
+
+func (e *EventTiming) handleEvent(ctx context.Context, event *v1.Event) error {
+	arrived := time.Now()
+
+	var slot phase0.Slot
+
+	switch data := event.Data.(type) {
+	case *v1.BlockEvent:
+		slot = data.Slot
+	case *v1.HeadEvent:
+		slot = data.Slot
+	case *v1.BlobSidecarEvent:
+		slot = data.Slot
+	case *v1.DataColumnSidecarEvent:
+		slot = data.Slot
+	case *electra.SingleAttestation:
+		if data.Data == nil {
+			return nil
+		}
+
+		slot = data.Data.Slot
+	case *v1.PayloadAttributesEvent:
+		if data.Data == nil {
+			return nil
+		}
+
+		slot = data.Data.ProposalSlot
+	default:
+		return nil
+	}
+
+	// Events received while syncing are for historical slots and would skew the histograms.
+	if e.beacon.Status().Syncing() {
+		return nil
+	}
+
+	genesis, err := e.beacon.Genesis()
+	if err != nil {
+		return err
+	}
+
+	spec, err := e.beacon.Spec()
+	if err != nil {
+		return err
+	}
+
+	slotStart := genesis.GenesisTime.Add(time.Duration(spec.SecondsPerSlot) * time.Duration(slot))
+
+	e.Arrival.WithLabelValues(event.Topic).Observe(arrived.Sub(slotStart).Seconds())
+
+	return nil
+}
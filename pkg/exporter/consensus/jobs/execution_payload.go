@@ -0,0 +1,302 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	eth2spec "github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api"
+	"github.com/ethpandaops/ethwallclock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// ExecutionPayloadConfig configures the execution payload job.
+type ExecutionPayloadConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// ExecutionPayloadTopics are the event stream topics the execution payload job depends on. Some
+// beacon nodes only publish payload_attributes while they prepare payloads for a proposer, e.g.
+// Lighthouse without --always-prepare-payload, in which case the attributes to block timing only
+// covers those slots.
+var ExecutionPayloadTopics = []string{"head", "block", "payload_attributes"}
+
+// ExecutionPayload exposes the execution payload of the head block, whether blocks were imported
+// optimistically and how long blocks take to arrive after the payload attributes for their slot.
+type ExecutionPayload struct {
+	beacon  beacon.Node
+	api     api.ConsensusClient
+	log     logrus.FieldLogger
+	watched *WatchedValidators
+
+	mu       *sync.Mutex
+	lastRoot phase0.Root
+	// attributes holds the arrival time and proposer of the first payload attributes event per proposal slot.
+	attributes map[phase0.Slot]payloadAttributesArrival
+
+	HeadBlockNumber     prometheus.Gauge
+	HeadBlockHash       prometheus.GaugeVec
+	ExecutionOptimistic prometheus.Gauge
+	OptimisticBlocks    prometheus.Counter
+	ExecutionOffline    prometheus.Gauge
+	AttributesToBlock   prometheus.HistogramVec
+}
+
+type payloadAttributesArrival struct {
+	arrived  time.Time
+	proposer phase0.ValidatorIndex
+}
+
+const (
+	NameExecutionPayload = "execution_payload"
+
+	// maxPayloadAttributesAge is the number of slots payload attributes are kept waiting for their block.
+	maxPayloadAttributesAge = 32
+)
+
+func (e *ExecutionPayload) Name() string {
+	return NameExecutionPayload
+}
+
+// NewExecutionPayload returns a new ExecutionPayload instance. Blocks proposed by the validators are
+// labelled separately in the proposal timing histogram.
+func NewExecutionPayload(beac beacon.Node, consensusAPI api.ConsensusClient, log logrus.FieldLogger, namespace string, constLabels map[string]string, watched *WatchedValidators) ExecutionPayload {
+	constLabels["module"] = NameExecutionPayload
+
+	namespace += "_execution_payload"
+
+	return ExecutionPayload{
+		beacon:     beac,
+		api:        consensusAPI,
+		log:        log.WithField("module", NameExecutionPayload),
+		watched:    watched,
+		mu:         &sync.Mutex{},
+		attributes: make(map[phase0.Slot]payloadAttributesArrival),
+		HeadBlockNumber: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "head_block_number",
+				Help:        "The execution block number in the payload of the head block.",
+				ConstLabels: constLabels,
+			},
+		),
+		HeadBlockHash: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "head_block_hash_info",
+				Help:        "The execution block hash in the payload of the head block.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"block_hash",
+			},
+		),
+		ExecutionOptimistic: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "execution_optimistic",
+				Help:        "Whether the most recently imported block was imported optimistically, without its payload being verified by the execution node (1 for optimistic).",
+				ConstLabels: constLabels,
+			},
+		),
+		OptimisticBlocks: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "optimistic_blocks_total",
+				Help:        "The number of blocks that were imported optimistically.",
+				ConstLabels: constLabels,
+			},
+		),
+		ExecutionOffline: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "execution_offline",
+				Help:        "Whether the beacon node reports its execution node as offline (1 for offline). The optimistic status of the node is exported by the beacon library.",
+				ConstLabels: constLabels,
+			},
+		),
+		AttributesToBlock: *prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   namespace,
+				Name:        "attributes_to_block_seconds",
+				Help:        "The time from the payload attributes event of a slot until the block of the slot was imported (in seconds).",
+				ConstLabels: constLabels,
+				Buckets:     []float64{0.5, 1, 2, 3, 4, 5, 6, 7, 8, 10, 12, 16},
+			},
+			[]string{
+				"proposer",
+			},
+		),
+	}
+}
+
+// Start registers the job with the beacon node.
+func (e *ExecutionPayload) Start(ctx context.Context) {
+	e.beacon.OnEvent(ctx, e.handleEvent)
+	e.beacon.OnBlock(ctx, e.handleBlock)
+
+	e.beacon.OnHead(ctx, func(ctx context.Context, event *v1.HeadEvent) error {
+		go e.observeHead(ctx, event)
+
+		return nil
+	})
+
+	e.beacon.OnReady(ctx, func(ctx context.Context, event *beacon.ReadyEvent) error {
+		e.beacon.Wallclock().OnSlotChanged(func(slot ethwallclock.Slot) {
+			e.observeSyncStatus(ctx)
+		})
+
+		e.observeSyncStatus(ctx)
+
+		if e.watched.Empty() {
+			return nil
+		}
+
+		e.beacon.Wallclock().OnEpochChanged(func(epoch ethwallclock.Epoch) {
+			e.resolveValidators(ctx)
+		})
+
+		e.resolveValidators(ctx)
+
+		return nil
+	})
+}
+
+func (e *ExecutionPayload) observeSyncStatus(ctx context.Context) {
+	status, err := e.api.SyncStatus(ctx)
+	if err != nil {
+		e.log.WithError(err).Error("Failed to get sync status")
+
+		return
+	}
+
+	if status.ELOffline {
+		e.ExecutionOffline.Set(1)
+	} else {
+		e.ExecutionOffline.Set(0)
+	}
+}
+
+func (e *ExecutionPayload) resolveValidators(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, err := e.watched.Resolve(ctx, e.beacon); err != nil {
+		e.log.WithError(err).Error("Failed to resolve watched validators")
+	}
+}
+
+func (e *ExecutionPayload) handleEvent(ctx context.Context, event *v1.Event) error {
+	data, ok := event.Data.(*v1.PayloadAttributesEvent)
+	if !ok || data.Data == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Nodes publish new attributes when the head changes, the first ones are when block building starts.
+	if _, ok := e.attributes[data.Data.ProposalSlot]; ok {
+		return nil
+	}
+
+	e.attributes[data.Data.ProposalSlot] = payloadAttributesArrival{
+		arrived:  time.Now(),
+		proposer: data.Data.ProposerIndex,
+	}
+
+	return nil
+}
+
+func (e *ExecutionPayload) handleBlock(ctx context.Context, event *v1.BlockEvent) error {
+	imported := time.Now()
+
+	if event.ExecutionOptimistic {
+		e.ExecutionOptimistic.Set(1)
+		e.OptimisticBlocks.Inc()
+	} else {
+		e.ExecutionOptimistic.Set(0)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for slot := range e.attributes {
+		if slot+maxPayloadAttributesAge < event.Slot {
+			delete(e.attributes, slot)
+		}
+	}
+
+	attributes, ok := e.attributes[event.Slot]
+	if !ok {
+		return nil
+	}
+
+	delete(e.attributes, event.Slot)
+
+	// Blocks imported while syncing are for historical slots and would skew the histogram.
+	if e.beacon.Status().Syncing() {
+		return nil
+	}
+
+	proposer := "other"
+	if e.watched.Contains(attributes.proposer) {
+		proposer = "watched"
+	}
+
+	e.AttributesToBlock.WithLabelValues(proposer).Observe(imported.Sub(attributes.arrived).Seconds())
+
+	return nil
+}
+
+func (e *ExecutionPayload) observeHead(ctx context.Context, event *v1.HeadEvent) {
+	e.mu.Lock()
+
+	if e.lastRoot == event.Block {
+		e.mu.Unlock()
+
+		return
+	}
+
+	e.lastRoot = event.Block
+
+	e.mu.Unlock()
+
+	root := event.Block.String()
+
+	block, err := e.beacon.FetchBlock(ctx, root)
+	if err != nil {
+		e.log.WithError(err).WithField("block", root).Warn("Failed to fetch head block")
+
+		return
+	}
+
+	// Blocks before Bellatrix do not carry an execution payload.
+	if block.Version < eth2spec.DataVersionBellatrix {
+		return
+	}
+
+	number, err := block.ExecutionBlockNumber()
+	if err != nil {
+		e.log.WithError(err).WithField("block", root).Warn("Failed to get execution block number")
+
+		return
+	}
+
+	hash, err := block.ExecutionBlockHash()
+	if err != nil {
+		e.log.WithError(err).WithField("block", root).Warn("Failed to get execution block hash")
+
+		return
+	}
+
+	e.HeadBlockNumber.Set(float64(number))
+
+	e.HeadBlockHash.Reset()
+	e.HeadBlockHash.WithLabelValues(hash.String()).Set(1)
+}
@@ -0,0 +1,326 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api"
+	execapi "github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/execution/api"
+	"github.com/ethpandaops/ethwallclock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// ForkSchedule exposes the current fork and a countdown to the next scheduled fork, including
+// blob parameter only (BPO) forks from the blob schedule.
+type ForkSchedule struct {
+	beacon    beacon.Node
+	api       api.ConsensusClient
+	execution execapi.ExecutionClient
+	log       logrus.FieldLogger
+
+	mu   *sync.Mutex
+	next *scheduledFork
+
+	CurrentInfo            prometheus.GaugeVec
+	NextEpoch              prometheus.GaugeVec
+	NextTimestamp          prometheus.GaugeVec
+	NextSeconds            prometheus.GaugeVec
+	NextExecutionSupported prometheus.GaugeVec
+}
+
+type scheduledFork struct {
+	name      string
+	version   string
+	epoch     phase0.Epoch
+	timestamp time.Time
+}
+
+const (
+	NameForkSchedule = "fork_schedule"
+)
+
+func (f *ForkSchedule) Name() string {
+	return NameForkSchedule
+}
+
+// NewForkSchedule returns a new ForkSchedule instance. The execution client is optional and
+// is used to check whether the execution node advertises support for the next fork via eth_config.
+func NewForkSchedule(beac beacon.Node, consensusAPI api.ConsensusClient, executionAPI execapi.ExecutionClient, log logrus.FieldLogger, namespace string, constLabels map[string]string) ForkSchedule {
+	constLabels["module"] = NameForkSchedule
+
+	namespace += "_fork_schedule"
+
+	return ForkSchedule{
+		beacon:    beac,
+		api:       consensusAPI,
+		execution: executionAPI,
+		log:       log.WithField("module", NameForkSchedule),
+		mu:        &sync.Mutex{},
+		CurrentInfo: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "current_info",
+				Help:        "The current fork (1 for the current fork).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"fork",
+				"version",
+			},
+		),
+		NextEpoch: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "next_epoch",
+				Help:        "The activation epoch of the next scheduled fork.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"fork",
+			},
+		),
+		NextTimestamp: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "next_timestamp_seconds",
+				Help:        "The unix timestamp at which the next scheduled fork activates.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"fork",
+			},
+		),
+		NextSeconds: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "next_seconds",
+				Help:        "The number of seconds until the next scheduled fork activates.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"fork",
+			},
+		),
+		NextExecutionSupported: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "next_execution_supported",
+				Help:        "Whether the execution node advertises the next scheduled fork via eth_config (1 for supported).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"fork",
+			},
+		),
+	}
+}
+
+// Start registers the job with the beacon node.
+func (f *ForkSchedule) Start(ctx context.Context) {
+	f.beacon.OnReady(ctx, func(ctx context.Context, event *beacon.ReadyEvent) error {
+		f.beacon.Wallclock().OnEpochChanged(func(epoch ethwallclock.Epoch) {
+			f.tick(ctx, phase0.Epoch(epoch.Number()))
+		})
+
+		f.beacon.Wallclock().OnSlotChanged(func(slot ethwallclock.Slot) {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			f.updateCountdown()
+		})
+
+		epoch := f.beacon.Wallclock().Epochs().Current()
+
+		f.tick(ctx, phase0.Epoch(epoch.Number()))
+
+		return nil
+	})
+}
+
+func (f *ForkSchedule) tick(ctx context.Context, current phase0.Epoch) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	forks, err := f.schedule(ctx)
+	if err != nil {
+		f.log.WithError(err).Error("Failed to get fork schedule")
+
+		return
+	}
+
+	var (
+		active *scheduledFork
+		next   *scheduledFork
+	)
+
+	for i := range forks {
+		fork := forks[i]
+
+		if fork.epoch <= current {
+			active = &fork
+		} else if next == nil {
+			next = &fork
+		}
+	}
+
+	f.CurrentInfo.Reset()
+
+	if active != nil {
+		f.CurrentInfo.WithLabelValues(active.name, active.version).Set(1)
+	}
+
+	f.next = next
+
+	f.NextEpoch.Reset()
+	f.NextTimestamp.Reset()
+	f.NextExecutionSupported.Reset()
+
+	if next != nil {
+		f.NextEpoch.WithLabelValues(next.name).Set(float64(next.epoch))
+		f.NextTimestamp.WithLabelValues(next.name).Set(float64(next.timestamp.Unix()))
+
+		f.observeExecutionSupport(ctx, next)
+	}
+
+	f.updateCountdown()
+}
+
+func (f *ForkSchedule) updateCountdown() {
+	f.NextSeconds.Reset()
+
+	if f.next == nil {
+		return
+	}
+
+	f.NextSeconds.WithLabelValues(f.next.name).Set(time.Until(f.next.timestamp).Seconds())
+}
+
+// schedule returns the named forks and BPO forks ordered by activation epoch.
+func (f *ForkSchedule) schedule(ctx context.Context) ([]scheduledFork, error) {
+	spec, err := f.beacon.Spec()
+	if err != nil {
+		return nil, err
+	}
+
+	genesis, err := f.beacon.Genesis()
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[phase0.Epoch]string)
+
+	schedule, err := f.api.ForkSchedule(ctx)
+	if err != nil {
+		f.log.WithError(err).Debug("Failed to fetch fork schedule, fork versions will be empty")
+	}
+
+	for _, fork := range schedule {
+		versions[fork.Epoch] = fork.CurrentVersion
+	}
+
+	epochDuration := time.Duration(spec.SecondsPerSlot) * time.Duration(spec.SlotsPerEpoch)
+
+	timestamp := func(epoch phase0.Epoch) time.Time {
+		return genesis.GenesisTime.Add(epochDuration * time.Duration(epoch))
+	}
+
+	forks := []scheduledFork{}
+	forkEpochs := make(map[phase0.Epoch]bool)
+
+	for _, fork := range spec.ForkEpochs {
+		// Forks that are not scheduled yet use the far future epoch.
+		if uint64(fork.Epoch) == math.MaxUint64 {
+			continue
+		}
+
+		forkEpochs[fork.Epoch] = true
+
+		forks = append(forks, scheduledFork{
+			name:      fork.Name.String(),
+			version:   versions[fork.Epoch],
+			epoch:     fork.Epoch,
+			timestamp: timestamp(fork.Epoch),
+		})
+	}
+
+	// Blob parameter only forks are numbered from 1, skipping entries that coincide with a named fork.
+	bpo := 0
+
+	for _, entry := range sortedBlobSchedule(spec.BlobSchedule) {
+		if forkEpochs[entry.Epoch] || uint64(entry.Epoch) == math.MaxUint64 {
+			continue
+		}
+
+		bpo++
+
+		forks = append(forks, scheduledFork{
+			name:      fmt.Sprintf("bpo%d", bpo),
+			version:   versions[entry.Epoch],
+			epoch:     entry.Epoch,
+			timestamp: timestamp(entry.Epoch),
+		})
+	}
+
+	// Forks sharing an epoch (e.g. at genesis) keep the canonical fork order, so the latest one wins.
+	sort.SliceStable(forks, func(i, j int) bool {
+		if forks[i].epoch != forks[j].epoch {
+			return forks[i].epoch < forks[j].epoch
+		}
+
+		return forkOrder(spec, forks[i].name) < forkOrder(spec, forks[j].name)
+	})
+
+	return forks, nil
+}
+
+func sortedBlobSchedule(schedule state.BlobSchedule) state.BlobSchedule {
+	sorted := make(state.BlobSchedule, len(schedule))
+	copy(sorted, schedule)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Epoch < sorted[j].Epoch
+	})
+
+	return sorted
+}
+
+func forkOrder(spec *state.Spec, name string) int {
+	fork, err := spec.ForkEpochs.GetByName(name)
+	if err != nil {
+		return len(state.ForkOrder)
+	}
+
+	return spec.ForkEpochs.IndexOf(fork.Name)
+}
+
+func (f *ForkSchedule) observeExecutionSupport(ctx context.Context, next *scheduledFork) {
+	if f.execution == nil {
+		return
+	}
+
+	config, err := f.execution.EthConfig(ctx)
+	if err != nil {
+		f.log.WithError(err).Debug("Failed to get eth_config from the execution node")
+
+		return
+	}
+
+	activation := uint64(next.timestamp.Unix())
+
+	supported := 0.0
+
+	if (config.Next != nil && config.Next.ActivationTime == activation) ||
+		(config.Last != nil && config.Last.ActivationTime >= activation) {
+		supported = 1
+	}
+
+	f.NextExecutionSupported.WithLabelValues(next.name).Set(supported)
+}
@@ -0,0 +1,232 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api/types"
+	"github.com/ethpandaops/ethwallclock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// Identity exposes the identity, ENR and gossip subscriptions of the beacon node.
+type Identity struct {
+	beacon beacon.Node
+	api    api.ConsensusClient
+	log    logrus.FieldLogger
+
+	// listeners are notified of every fetched identity, so that other jobs do not fetch it again.
+	listeners []func(identity *types.Identity)
+
+	Info              prometheus.GaugeVec
+	ENRSeqNumber      prometheus.Gauge
+	MetadataSeqNumber prometheus.Gauge
+	Attnets           prometheus.Gauge
+	Syncnets          prometheus.Gauge
+	CustodyGroupCount prometheus.Gauge
+	Addresses         prometheus.GaugeVec
+}
+
+const (
+	NameIdentity = "identity"
+)
+
+func (i *Identity) Name() string {
+	return NameIdentity
+}
+
+// NewIdentity returns a new Identity instance.
+func NewIdentity(beac beacon.Node, consensusAPI api.ConsensusClient, log logrus.FieldLogger, namespace string, constLabels map[string]string) Identity {
+	constLabels["module"] = NameIdentity
+
+	namespace += "_node"
+
+	return Identity{
+		beacon: beac,
+		api:    consensusAPI,
+		log:    log.WithField("module", NameIdentity),
+		Info: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "identity_info",
+				Help:        "The identity of the beacon node as advertised in its ENR.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"peer_id",
+				"ip",
+				"tcp_port",
+				"udp_port",
+			},
+		),
+		ENRSeqNumber: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "enr_seq_number",
+				Help:        "The sequence number of the node's ENR.",
+				ConstLabels: constLabels,
+			},
+		),
+		MetadataSeqNumber: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "metadata_seq_number",
+				Help:        "The sequence number of the node's p2p metadata.",
+				ConstLabels: constLabels,
+			},
+		),
+		Attnets: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "attnets",
+				Help:        "The number of attestation subnets the node advertises.",
+				ConstLabels: constLabels,
+			},
+		),
+		Syncnets: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "syncnets",
+				Help:        "The number of sync committee subnets the node advertises.",
+				ConstLabels: constLabels,
+			},
+		),
+		CustodyGroupCount: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "custody_group_count",
+				Help:        "The number of custody groups the node advertises in its metadata.",
+				ConstLabels: constLabels,
+			},
+		),
+		Addresses: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "address_info",
+				Help:        "The p2p and discovery addresses the node is listening on.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"kind",
+				"address",
+			},
+		),
+	}
+}
+
+// Start registers the job with the beacon node.
+func (i *Identity) Start(ctx context.Context) {
+	i.beacon.OnReady(ctx, func(ctx context.Context, event *beacon.ReadyEvent) error {
+		i.beacon.Wallclock().OnEpochChanged(func(epoch ethwallclock.Epoch) {
+			i.tick(ctx)
+		})
+
+		i.tick(ctx)
+
+		return nil
+	})
+}
+
+func (i *Identity) tick(ctx context.Context) {
+	identity, err := i.api.NodeIdentity(ctx)
+	if err != nil {
+		i.log.WithError(err).Error("Failed to get node identity")
+
+		return
+	}
+
+	i.ObserveIdentity(identity)
+
+	for _, listener := range i.listeners {
+		listener(identity)
+	}
+}
+
+// OnIdentity registers a listener that is called with the node identity whenever it is fetched.
+// It must be called before Start.
+func (i *Identity) OnIdentity(listener func(identity *types.Identity)) {
+	i.listeners = append(i.listeners, listener)
+}
+
+// ObserveIdentity updates the metrics from the node identity.
+func (i *Identity) ObserveIdentity(identity *types.Identity) {
+	ip, tcp, udp := "", "", ""
+
+	if identity.ENR != "" {
+		var node enode.Node
+
+		if err := node.UnmarshalText([]byte(identity.ENR)); err != nil {
+			i.log.WithError(err).Warn("Failed to decode node ENR")
+		} else {
+			if node.IP() != nil {
+				ip = node.IP().String()
+			}
+
+			tcp = strconv.Itoa(node.TCP())
+			udp = strconv.Itoa(node.UDP())
+
+			i.ENRSeqNumber.Set(float64(node.Seq()))
+		}
+	}
+
+	i.Info.Reset()
+	i.Info.WithLabelValues(identity.PeerID, ip, tcp, udp).Set(1)
+
+	if seq, err := strconv.ParseUint(identity.Metadata.SeqNumber, 10, 64); err == nil {
+		i.MetadataSeqNumber.Set(float64(seq))
+	}
+
+	if count, err := bitfieldCount(identity.Metadata.Attnets); err == nil {
+		i.Attnets.Set(float64(count))
+	} else {
+		i.log.WithError(err).Debug("Failed to decode attnets")
+	}
+
+	if count, err := bitfieldCount(identity.Metadata.Syncnets); err == nil {
+		i.Syncnets.Set(float64(count))
+	} else {
+		i.log.WithError(err).Debug("Failed to decode syncnets")
+	}
+
+	if identity.Metadata.CustodyGroupCount != "" {
+		if count, err := strconv.ParseUint(identity.Metadata.CustodyGroupCount, 10, 64); err == nil {
+			i.CustodyGroupCount.Set(float64(count))
+		}
+	}
+
+	i.Addresses.Reset()
+
+	for _, address := range identity.P2PAddresses {
+		i.Addresses.WithLabelValues("p2p", address).Set(1)
+	}
+
+	for _, address := range identity.DiscoveryAddresses {
+		i.Addresses.WithLabelValues("discovery", address).Set(1)
+	}
+}
+
+// bitfieldCount returns the number of set bits in a hex encoded bitvector.
+func bitfieldCount(bitfield string) (int, error) {
+	if bitfield == "" {
+		return 0, nil
+	}
+
+	raw, err := hexutil.Decode(bitfield)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bitfield %q: %w", bitfield, err)
+	}
+
+	count := 0
+	for _, b := range raw {
+		count += bits.OnesCount8(b)
+	}
+
+	return count, nil
+}
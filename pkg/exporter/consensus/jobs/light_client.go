@@ -0,0 +1,214 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api/types"
+	"github.com/ethpandaops/ethwallclock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// LightClientConfig configures the light client serving job.
+type LightClientConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// LightClient verifies that the beacon node serves light client updates and bootstraps.
+type LightClient struct {
+	beacon beacon.Node
+	api    api.ConsensusClient
+	log    logrus.FieldLogger
+
+	mu *sync.Mutex
+	// bootstrapRoot is the finalized root the bootstrap was last checked for.
+	bootstrapRoot phase0.Root
+
+	Served   prometheus.GaugeVec
+	Latency  prometheus.HistogramVec
+	SlotLag  prometheus.GaugeVec
+	Failures prometheus.CounterVec
+}
+
+const (
+	NameLightClient = "light_client"
+
+	lightClientFinalityUpdate   = "finality_update"
+	lightClientOptimisticUpdate = "optimistic_update"
+	lightClientBootstrap        = "bootstrap"
+)
+
+func (l *LightClient) Name() string {
+	return NameLightClient
+}
+
+// NewLightClient returns a new LightClient instance.
+func NewLightClient(beac beacon.Node, consensusAPI api.ConsensusClient, log logrus.FieldLogger, namespace string, constLabels map[string]string) LightClient {
+	constLabels["module"] = NameLightClient
+
+	namespace += "_light_client"
+
+	return LightClient{
+		beacon: beac,
+		api:    consensusAPI,
+		log:    log.WithField("module", NameLightClient),
+		mu:     &sync.Mutex{},
+		Served: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "served",
+				Help:        "Whether the light client endpoint was served on the last check (1 for served).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"endpoint",
+			},
+		),
+		Latency: *prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   namespace,
+				Name:        "response_seconds",
+				Help:        "The response time of the light client endpoints (in seconds).",
+				ConstLabels: constLabels,
+				Buckets:     []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+			},
+			[]string{
+				"endpoint",
+			},
+		),
+		SlotLag: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "slot_lag",
+				Help:        "The number of slots the headers of the latest light client updates lag behind the head.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"update",
+				"header",
+			},
+		),
+		Failures: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "failures_total",
+				Help:        "The number of light client requests that were not served.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"endpoint",
+			},
+		),
+	}
+}
+
+// Start registers the job with the beacon node.
+func (l *LightClient) Start(ctx context.Context) {
+	l.beacon.OnReady(ctx, func(ctx context.Context, event *beacon.ReadyEvent) error {
+		l.beacon.Wallclock().OnSlotChanged(func(slot ethwallclock.Slot) {
+			l.tick(ctx)
+		})
+
+		l.tick(ctx)
+
+		return nil
+	})
+}
+
+func (l *LightClient) tick(ctx context.Context) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Light client data is only served once the node is synced.
+	if l.beacon.Status().Syncing() {
+		return
+	}
+
+	head, err := l.api.BlockHeader(ctx, "head")
+	if err != nil {
+		l.log.WithError(err).Error("Failed to get head block header")
+
+		return
+	}
+
+	headSlot := head.Header.Message.Slot
+
+	if update, ok := l.fetchUpdate(ctx, lightClientFinalityUpdate, l.api.LightClientFinalityUpdate); ok {
+		l.observeLag(lightClientFinalityUpdate, "attested", headSlot, update.AttestedHeader.Beacon.Slot)
+
+		if update.FinalizedHeader != nil {
+			l.observeLag(lightClientFinalityUpdate, "finalized", headSlot, update.FinalizedHeader.Beacon.Slot)
+		}
+	}
+
+	if update, ok := l.fetchUpdate(ctx, lightClientOptimisticUpdate, l.api.LightClientOptimisticUpdate); ok {
+		l.observeLag(lightClientOptimisticUpdate, "attested", headSlot, update.AttestedHeader.Beacon.Slot)
+	}
+
+	l.checkBootstrap(ctx)
+}
+
+func (l *LightClient) fetchUpdate(ctx context.Context, endpoint string, fetch func(ctx context.Context) (*types.LightClientUpdate, error)) (*types.LightClientUpdate, bool) {
+	start := time.Now()
+
+	update, err := fetch(ctx)
+
+	l.observeServed(endpoint, start, err)
+
+	return update, err == nil
+}
+
+// checkBootstrap checks that a bootstrap is served for the finalized block root, which is what
+// light clients sync from. It is only checked again once the finalized root changes, or after a
+// failure.
+func (l *LightClient) checkBootstrap(ctx context.Context) {
+	checkpoints, err := l.api.FinalityCheckpoints(ctx, "head")
+	if err != nil {
+		l.log.WithError(err).Error("Failed to get finality checkpoints")
+
+		return
+	}
+
+	// There is no bootstrap before the first finalized checkpoint.
+	if checkpoints.Finalized.Root == (phase0.Root{}) {
+		return
+	}
+
+	if checkpoints.Finalized.Root == l.bootstrapRoot {
+		return
+	}
+
+	start := time.Now()
+
+	_, err = l.api.LightClientBootstrap(ctx, checkpoints.Finalized.Root)
+
+	l.observeServed(lightClientBootstrap, start, err)
+
+	if err == nil {
+		l.bootstrapRoot = checkpoints.Finalized.Root
+	}
+}
+
+func (l *LightClient) observeServed(endpoint string, start time.Time, err error) {
+	l.Latency.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		l.log.WithError(err).WithField("endpoint", endpoint).Debug("Light client endpoint was not served")
+
+		l.Served.WithLabelValues(endpoint).Set(0)
+		l.Failures.WithLabelValues(endpoint).Inc()
+
+		return
+	}
+
+	l.Served.WithLabelValues(endpoint).Set(1)
+}
+
+func (l *LightClient) observeLag(update, header string, head, slot phase0.Slot) {
+	l.SlotLag.WithLabelValues(update, header).Set(float64(head) - float64(slot))
+}
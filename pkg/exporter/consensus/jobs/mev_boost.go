@@ -0,0 +1,404 @@
+package jobs
+
+import (
+	"context"
+	"math/big"
+	"net/url"
+	"sync"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api"
+	"github.com/ethpandaops/ethwallclock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// MevBoostConfig configures monitoring of mev-boost and the relays it is connected to.
+type MevBoostConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// URL is the builder API of mev-boost.
+	URL string `yaml:"url"`
+	// Relays are the relay URLs, optionally including the relay pubkey as user info.
+	Relays []string `yaml:"relays"`
+}
+
+// MevBoost exposes the availability of mev-boost and its relays, and the payloads the relays
+// delivered for the watched validators.
+type MevBoost struct {
+	beacon   beacon.Node
+	log      logrus.FieldLogger
+	mevBoost api.RelayClient
+	relays   map[string]api.RelayClient
+	watched  *WatchedValidators
+
+	mu *sync.Mutex
+	// lastEpochs holds the last epoch whose proposals were checked for delivered payloads, per relay.
+	lastEpochs map[string]phase0.Epoch
+
+	registering *sync.Mutex
+	// registered caches the watched validators known to be registered, per relay.
+	registered map[string]map[phase0.BLSPubKey]bool
+	// registrationsRefreshed is the epoch the registrations of every watched validator were last looked up.
+	registrationsRefreshed *phase0.Epoch
+
+	Up                   prometheus.Gauge
+	RelayUp              prometheus.GaugeVec
+	RelayLatency         prometheus.HistogramVec
+	RelayRegistered      prometheus.GaugeVec
+	RelayBlocksDelivered prometheus.CounterVec
+	RelayValueDelivered  prometheus.CounterVec
+	RelayErrors          prometheus.CounterVec
+}
+
+const (
+	NameMevBoost = "mev_boost"
+
+	// registrationRefreshEpochs is how often validators already known to be registered are looked
+	// up again. Unregistered validators are looked up every epoch.
+	registrationRefreshEpochs = 32
+	// maxDeliveredCatchUpEpochs bounds how many epochs are checked for delivered payloads after
+	// relay failures.
+	maxDeliveredCatchUpEpochs = 4
+)
+
+func (m *MevBoost) Name() string {
+	return NameMevBoost
+}
+
+// NewMevBoost returns a new MevBoost instance.
+func NewMevBoost(beac beacon.Node, log logrus.FieldLogger, namespace string, constLabels map[string]string, config MevBoostConfig, watched *WatchedValidators) MevBoost {
+	constLabels["module"] = NameMevBoost
+
+	namespace += "_mev_boost"
+
+	relays := make(map[string]api.RelayClient, len(config.Relays))
+
+	for _, relay := range config.Relays {
+		relays[relayName(relay)] = api.NewRelayClient(relay)
+	}
+
+	var mevBoost api.RelayClient
+	if config.URL != "" {
+		mevBoost = api.NewRelayClient(config.URL)
+	}
+
+	return MevBoost{
+		beacon:      beac,
+		log:         log.WithField("module", NameMevBoost),
+		mevBoost:    mevBoost,
+		relays:      relays,
+		watched:     watched,
+		mu:          &sync.Mutex{},
+		lastEpochs:  make(map[string]phase0.Epoch),
+		registering: &sync.Mutex{},
+		registered:  make(map[string]map[phase0.BLSPubKey]bool),
+		Up: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "up",
+				Help:        "Whether mev-boost reports its builder API as available (1 for available).",
+				ConstLabels: constLabels,
+			},
+		),
+		RelayUp: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "relay_up",
+				Help:        "Whether the relay reports its builder API as available (1 for available).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"relay",
+			},
+		),
+		RelayLatency: *prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   namespace,
+				Name:        "relay_latency_seconds",
+				Help:        "The latency of the relay status requests (in seconds).",
+				ConstLabels: constLabels,
+				Buckets:     []float64{0.025, 0.05, 0.1, 0.25, 0.5, 1, 2, 5},
+			},
+			[]string{
+				"relay",
+			},
+		),
+		RelayRegistered: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "relay_registered_validators",
+				Help:        "The number of watched validators that are registered with the relay.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"relay",
+			},
+		),
+		RelayBlocksDelivered: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "relay_blocks_delivered_total",
+				Help:        "The number of payloads the relay delivered for blocks proposed by watched validators.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"relay",
+			},
+		),
+		RelayValueDelivered: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "relay_value_delivered_gwei_total",
+				Help:        "The value of the payloads the relay delivered for blocks proposed by watched validators (in gwei).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"relay",
+			},
+		),
+		RelayErrors: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "relay_errors_total",
+				Help:        "The number of failed requests to the relay data API.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"relay",
+			},
+		),
+	}
+}
+
+// relayName returns the host of a relay URL, so that relay pubkeys do not end up in labels.
+func relayName(relay string) string {
+	u, err := url.Parse(relay)
+	if err != nil || u.Host == "" {
+		return relay
+	}
+
+	return u.Host
+}
+
+// Start registers the job with the beacon node.
+func (m *MevBoost) Start(ctx context.Context) {
+	m.beacon.OnReady(ctx, func(ctx context.Context, event *beacon.ReadyEvent) error {
+		m.beacon.Wallclock().OnEpochChanged(func(epoch ethwallclock.Epoch) {
+			m.tick(ctx, phase0.Epoch(epoch.Number()))
+		})
+
+		epoch := m.beacon.Wallclock().Epochs().Current()
+
+		m.tick(ctx, phase0.Epoch(epoch.Number()))
+
+		return nil
+	})
+}
+
+func (m *MevBoost) tick(ctx context.Context, current phase0.Epoch) {
+	m.observeStatus(ctx)
+
+	if m.watched.Empty() || len(m.relays) == 0 {
+		return
+	}
+
+	validators, err := m.watched.Resolve(ctx, m.beacon)
+	if err != nil {
+		m.log.WithError(err).Error("Failed to resolve watched validators")
+
+		return
+	}
+
+	go m.observeRegistrations(ctx, current, validators)
+
+	if current == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Payloads are checked once the epoch of the proposals has ended.
+	m.observeDelivered(ctx, current-1)
+}
+
+func (m *MevBoost) observeStatus(ctx context.Context) {
+	if m.mevBoost != nil {
+		if err := m.mevBoost.Status(ctx); err != nil {
+			m.log.WithError(err).Warn("mev-boost status check failed")
+
+			m.Up.Set(0)
+		} else {
+			m.Up.Set(1)
+		}
+	}
+
+	for name, relay := range m.relays {
+		start := time.Now()
+
+		err := relay.Status(ctx)
+
+		m.RelayLatency.WithLabelValues(name).Observe(time.Since(start).Seconds())
+
+		if err != nil {
+			m.log.WithError(err).WithField("relay", name).Warn("Relay status check failed")
+
+			m.RelayUp.WithLabelValues(name).Set(0)
+
+			continue
+		}
+
+		m.RelayUp.WithLabelValues(name).Set(1)
+	}
+}
+
+// observeRegistrations counts the watched validators registered with each relay. Validators that
+// are known to be registered are only looked up again every registrationRefreshEpochs.
+func (m *MevBoost) observeRegistrations(ctx context.Context, epoch phase0.Epoch, validators map[phase0.ValidatorIndex]*v1.Validator) {
+	// Skip the lookups if the previous ones are still running.
+	if !m.registering.TryLock() {
+		return
+	}
+
+	defer m.registering.Unlock()
+
+	refresh := m.registrationsRefreshed == nil || *m.registrationsRefreshed+registrationRefreshEpochs <= epoch
+	refreshed := true
+
+	for name, relay := range m.relays {
+		known := m.registered[name]
+		registered := make(map[phase0.BLSPubKey]bool, len(validators))
+		failed := false
+
+		for _, validator := range validators {
+			if validator.Validator == nil {
+				continue
+			}
+
+			pubkey := validator.Validator.PublicKey
+
+			if known[pubkey] && !refresh {
+				registered[pubkey] = true
+
+				continue
+			}
+
+			ok, err := relay.ValidatorRegistered(ctx, pubkey)
+			if err != nil {
+				m.RelayErrors.WithLabelValues(name).Inc()
+
+				m.log.WithError(err).WithField("relay", name).Debug("Failed to check validator registration")
+
+				failed = true
+
+				break
+			}
+
+			if ok {
+				registered[pubkey] = true
+			}
+		}
+
+		// A partial count would look like validators dropping their registration.
+		if failed {
+			refreshed = false
+
+			continue
+		}
+
+		m.registered[name] = registered
+
+		m.RelayRegistered.WithLabelValues(name).Set(float64(len(registered)))
+	}
+
+	if refresh && refreshed {
+		m.registrationsRefreshed = &epoch
+	}
+}
+
+// observeDelivered counts the payloads each relay delivered for the proposals of the watched
+// validators, up to the given epoch. Epochs a relay failed for are checked again on the next
+// tick, so the last checked epoch of a relay only advances once all its requests succeeded.
+func (m *MevBoost) observeDelivered(ctx context.Context, epoch phase0.Epoch) {
+	duties := make(map[phase0.Epoch][]*v1.ProposerDuty)
+
+	for name, relay := range m.relays {
+		from := epoch
+
+		if last, ok := m.lastEpochs[name]; ok {
+			if last >= epoch {
+				continue
+			}
+
+			from = last + 1
+
+			if epoch-from >= maxDeliveredCatchUpEpochs {
+				from = epoch - maxDeliveredCatchUpEpochs + 1
+			}
+		}
+
+		for e := from; e <= epoch; e++ {
+			if _, ok := duties[e]; !ok {
+				fetched, err := m.beacon.FetchProposerDuties(ctx, e)
+				if err != nil {
+					m.log.WithError(err).WithField("epoch", e).Error("Failed to get proposer duties")
+
+					return
+				}
+
+				duties[e] = fetched
+			}
+
+			if !m.observeRelayDelivered(ctx, name, relay, duties[e]) {
+				break
+			}
+
+			m.lastEpochs[name] = e
+		}
+	}
+}
+
+// observeRelayDelivered counts the payloads the relay delivered for the proposals of the watched
+// validators among the duties. It returns false if any request failed, in which case nothing is
+// counted so that the epoch can be checked again.
+func (m *MevBoost) observeRelayDelivered(ctx context.Context, name string, relay api.RelayClient, duties []*v1.ProposerDuty) bool {
+	blocks := 0
+	value := 0.0
+
+	for _, duty := range duties {
+		if !m.watched.Contains(duty.ValidatorIndex) {
+			continue
+		}
+
+		payloads, err := relay.PayloadsDelivered(ctx, duty.Slot)
+		if err != nil {
+			m.RelayErrors.WithLabelValues(name).Inc()
+
+			m.log.WithError(err).WithField("relay", name).WithField("slot", duty.Slot).Debug("Failed to get delivered payloads")
+
+			return false
+		}
+
+		for _, payload := range payloads {
+			if payload.ProposerPubkey != duty.PubKey.String() {
+				continue
+			}
+
+			blocks++
+
+			if wei, ok := new(big.Int).SetString(payload.Value, 10); ok {
+				gwei, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e9)).Float64()
+
+				value += gwei
+			}
+		}
+	}
+
+	m.RelayBlocksDelivered.WithLabelValues(name).Add(float64(blocks))
+	m.RelayValueDelivered.WithLabelValues(name).Add(value)
+
+	return true
+}
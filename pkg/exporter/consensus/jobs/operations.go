@@ -0,0 +1,459 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	eth2spec "github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api"
+	"github.com/ethpandaops/ethwallclock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// OperationsConfig configures the slashing, exit and credential change job.
+type OperationsConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// OperationsTopics are the event stream topics the operations job depends on.
+var OperationsTopics = []string{"head"}
+
+// Operations counts proposer slashings, attester slashings, voluntary exits and BLS to execution
+// changes in head blocks and in the operation pool, and flags operations for watched validators.
+type Operations struct {
+	beacon  beacon.Node
+	api     api.ConsensusClient
+	log     logrus.FieldLogger
+	watched *WatchedValidators
+
+	mu       *sync.Mutex
+	lastRoot phase0.Root
+	// pool holds the keys of the operations in the pool at the last check, by operation.
+	pool map[string]map[string]struct{}
+
+	Included         prometheus.CounterVec
+	PoolSeen         prometheus.CounterVec
+	PoolSize         prometheus.GaugeVec
+	WatchedValidator prometheus.CounterVec
+}
+
+// operation is a single operation with the validators it applies to.
+type operation struct {
+	kind    string
+	key     string
+	indices []phase0.ValidatorIndex
+}
+
+const (
+	NameOperations = "operations"
+
+	operationProposerSlashing     = "proposer_slashing"
+	operationAttesterSlashing     = "attester_slashing"
+	operationVoluntaryExit        = "voluntary_exit"
+	operationBLSToExecutionChange = "bls_to_execution_change"
+
+	sourceBlock = "block"
+	sourcePool  = "pool"
+)
+
+var operationKinds = []string{operationProposerSlashing, operationAttesterSlashing, operationVoluntaryExit, operationBLSToExecutionChange}
+
+func (o *Operations) Name() string {
+	return NameOperations
+}
+
+// NewOperations returns a new Operations instance.
+func NewOperations(beac beacon.Node, consensusAPI api.ConsensusClient, log logrus.FieldLogger, namespace string, constLabels map[string]string, watched *WatchedValidators) Operations {
+	constLabels["module"] = NameOperations
+
+	namespace += "_operations"
+
+	return Operations{
+		beacon:  beac,
+		api:     consensusAPI,
+		log:     log.WithField("module", NameOperations),
+		watched: watched,
+		mu:      &sync.Mutex{},
+		pool:    make(map[string]map[string]struct{}),
+		Included: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "included_total",
+				Help:        "The number of operations included in head blocks.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"operation",
+			},
+		),
+		PoolSeen: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "pool_seen_total",
+				Help:        "The number of operations that appeared in the operation pool.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"operation",
+			},
+		),
+		PoolSize: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "pool_size",
+				Help:        "The number of operations in the operation pool.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"operation",
+			},
+		),
+		WatchedValidator: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "watched_validator_total",
+				Help:        "The number of operations seen for a watched validator. Any increase of a slashing should page.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"operation",
+				"source",
+				"index",
+				"name",
+			},
+		),
+	}
+}
+
+// Start registers the job with the beacon node.
+func (o *Operations) Start(ctx context.Context) {
+	for _, kind := range operationKinds {
+		o.Included.WithLabelValues(kind).Add(0)
+		o.PoolSeen.WithLabelValues(kind).Add(0)
+	}
+
+	o.beacon.OnHead(ctx, func(ctx context.Context, event *v1.HeadEvent) error {
+		go o.observeHead(ctx, event)
+
+		return nil
+	})
+
+	o.beacon.OnReady(ctx, func(ctx context.Context, event *beacon.ReadyEvent) error {
+		o.beacon.Wallclock().OnEpochChanged(func(epoch ethwallclock.Epoch) {
+			o.resolveValidators(ctx)
+		})
+
+		o.beacon.Wallclock().OnSlotChanged(func(slot ethwallclock.Slot) {
+			o.observePool(ctx)
+		})
+
+		o.resolveValidators(ctx)
+		o.observePool(ctx)
+
+		return nil
+	})
+}
+
+func (o *Operations) resolveValidators(ctx context.Context) {
+	if o.watched.Empty() {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, err := o.watched.Resolve(ctx, o.beacon); err != nil {
+		o.log.WithError(err).Error("Failed to resolve watched validators")
+
+		return
+	}
+
+	// Slashing series are created at 0 so that alerts on their increase fire on the first slashing.
+	for _, index := range o.watched.Indices() {
+		indexLabel, name := o.watched.Labels(index)
+
+		for _, kind := range []string{operationProposerSlashing, operationAttesterSlashing} {
+			for _, source := range []string{sourceBlock, sourcePool} {
+				o.WatchedValidator.WithLabelValues(kind, source, indexLabel, name).Add(0)
+			}
+		}
+	}
+}
+
+func (o *Operations) observeHead(ctx context.Context, event *v1.HeadEvent) {
+	o.mu.Lock()
+
+	if o.lastRoot == event.Block {
+		o.mu.Unlock()
+
+		return
+	}
+
+	o.lastRoot = event.Block
+
+	o.mu.Unlock()
+
+	root := event.Block.String()
+
+	block, err := o.beacon.FetchBlock(ctx, root)
+	if err != nil {
+		o.log.WithError(err).WithField("block", root).Warn("Failed to fetch head block")
+
+		return
+	}
+
+	operations, err := blockOperations(block)
+	if err != nil {
+		o.log.WithError(err).WithField("block", root).Warn("Failed to get block operations")
+
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, op := range operations {
+		o.Included.WithLabelValues(op.kind).Inc()
+
+		o.observeWatched(op, sourceBlock)
+	}
+}
+
+// blockOperations returns the slashings, exits and credential changes included in the block.
+func blockOperations(block *eth2spec.VersionedSignedBeaconBlock) ([]operation, error) {
+	operations := []operation{}
+
+	proposerSlashings, err := block.ProposerSlashings()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, slashing := range proposerSlashings {
+		if slashing.SignedHeader1 == nil || slashing.SignedHeader1.Message == nil {
+			continue
+		}
+
+		operations = append(operations, proposerSlashingOperation(slashing.SignedHeader1.Message.Slot, slashing.SignedHeader1.Message.ProposerIndex))
+	}
+
+	attesterSlashings, err := block.AttesterSlashings()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, slashing := range attesterSlashings {
+		attestation1, err := slashing.Attestation1()
+		if err != nil {
+			return nil, err
+		}
+
+		attestation2, err := slashing.Attestation2()
+		if err != nil {
+			return nil, err
+		}
+
+		indices1, err := attestation1.AttestingIndices()
+		if err != nil {
+			return nil, err
+		}
+
+		indices2, err := attestation2.AttestingIndices()
+		if err != nil {
+			return nil, err
+		}
+
+		operations = append(operations, attesterSlashingOperation(toValidatorIndices(indices1), toValidatorIndices(indices2)))
+	}
+
+	exits, err := block.VoluntaryExits()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, exit := range exits {
+		if exit.Message == nil {
+			continue
+		}
+
+		operations = append(operations, validatorOperation(operationVoluntaryExit, exit.Message.ValidatorIndex))
+	}
+
+	// BLS to execution changes were introduced in Capella.
+	if block.Version < eth2spec.DataVersionCapella {
+		return operations, nil
+	}
+
+	changes, err := block.BLSToExecutionChanges()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range changes {
+		if change.Message == nil {
+			continue
+		}
+
+		operations = append(operations, validatorOperation(operationBLSToExecutionChange, change.Message.ValidatorIndex))
+	}
+
+	return operations, nil
+}
+
+func (o *Operations) observePool(ctx context.Context) {
+	pool, err := o.poolOperations(ctx)
+	if err != nil {
+		o.log.WithError(err).Warn("Failed to get the operation pool")
+
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	current := make(map[string]map[string]struct{}, len(operationKinds))
+
+	for _, kind := range operationKinds {
+		current[kind] = make(map[string]struct{})
+	}
+
+	for _, op := range pool {
+		current[op.kind][op.key] = struct{}{}
+
+		// Operations stay in the pool until they are included, only new ones are counted.
+		if _, ok := o.pool[op.kind][op.key]; ok {
+			continue
+		}
+
+		o.PoolSeen.WithLabelValues(op.kind).Inc()
+
+		o.observeWatched(op, sourcePool)
+	}
+
+	for kind, keys := range current {
+		o.PoolSize.WithLabelValues(kind).Set(float64(len(keys)))
+	}
+
+	o.pool = current
+}
+
+func (o *Operations) poolOperations(ctx context.Context) ([]operation, error) {
+	operations := []operation{}
+
+	proposerSlashings, err := o.api.PoolProposerSlashings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proposer slashings: %w", err)
+	}
+
+	for _, slashing := range proposerSlashings {
+		operations = append(operations, proposerSlashingOperation(slashing.SignedHeader1.Message.Slot, slashing.SignedHeader1.Message.ProposerIndex))
+	}
+
+	attesterSlashings, err := o.api.PoolAttesterSlashings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attester slashings: %w", err)
+	}
+
+	for _, slashing := range attesterSlashings {
+		operations = append(operations, attesterSlashingOperation(slashing.Attestation1.AttestingIndices, slashing.Attestation2.AttestingIndices))
+	}
+
+	exits, err := o.api.PoolVoluntaryExits(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voluntary exits: %w", err)
+	}
+
+	for _, exit := range exits {
+		operations = append(operations, validatorOperation(operationVoluntaryExit, exit.Message.ValidatorIndex))
+	}
+
+	changes, err := o.api.PoolBLSToExecutionChanges(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get BLS to execution changes: %w", err)
+	}
+
+	for _, change := range changes {
+		operations = append(operations, validatorOperation(operationBLSToExecutionChange, change.Message.ValidatorIndex))
+	}
+
+	return operations, nil
+}
+
+// observeWatched flags the operation for every watched validator it applies to. The caller must
+// hold the lock.
+func (o *Operations) observeWatched(op operation, source string) {
+	for _, index := range op.indices {
+		if !o.watched.Contains(index) {
+			continue
+		}
+
+		indexLabel, name := o.watched.Labels(index)
+
+		o.WatchedValidator.WithLabelValues(op.kind, source, indexLabel, name).Inc()
+
+		log := o.log.WithField("operation", op.kind).WithField("source", source).WithField("index", indexLabel).WithField("name", name)
+
+		if op.kind == operationProposerSlashing || op.kind == operationAttesterSlashing {
+			log.Error("Watched validator is being slashed")
+
+			continue
+		}
+
+		log.Info("Operation seen for watched validator")
+	}
+}
+
+func proposerSlashingOperation(slot phase0.Slot, proposer phase0.ValidatorIndex) operation {
+	return operation{
+		kind:    operationProposerSlashing,
+		key:     fmt.Sprintf("%d/%d", slot, proposer),
+		indices: []phase0.ValidatorIndex{proposer},
+	}
+}
+
+// attesterSlashingOperation returns an attester slashing, which slashes the validators that
+// attested in both conflicting attestations.
+func attesterSlashingOperation(indices1, indices2 []phase0.ValidatorIndex) operation {
+	attested := make(map[phase0.ValidatorIndex]struct{}, len(indices1))
+	for _, index := range indices1 {
+		attested[index] = struct{}{}
+	}
+
+	slashed := []phase0.ValidatorIndex{}
+	keys := []string{}
+
+	for _, index := range indices2 {
+		if _, ok := attested[index]; ok {
+			slashed = append(slashed, index)
+			keys = append(keys, fmt.Sprintf("%d", index))
+		}
+	}
+
+	return operation{
+		kind:    operationAttesterSlashing,
+		key:     strings.Join(keys, ","),
+		indices: slashed,
+	}
+}
+
+func validatorOperation(kind string, index phase0.ValidatorIndex) operation {
+	return operation{
+		kind:    kind,
+		key:     fmt.Sprintf("%d", index),
+		indices: []phase0.ValidatorIndex{index},
+	}
+}
+
+func toValidatorIndices(indices []uint64) []phase0.ValidatorIndex {
+	validators := make([]phase0.ValidatorIndex, 0, len(indices))
+	for _, index := range indices {
+		validators = append(validators, phase0.ValidatorIndex(index))
+	}
+
+	return validators
+}
@@ -0,0 +1,282 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	eth2spec "github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// ParticipationConfig configures the network-wide participation job.
+type ParticipationConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// ParticipationTopics are the event stream topics the participation job depends on.
+var ParticipationTopics = []string{"head"}
+
+// Participation computes network-wide sync committee and attestation participation from the body
+// of every head block.
+type Participation struct {
+	beacon beacon.Node
+	log    logrus.FieldLogger
+
+	mu       *sync.Mutex
+	lastRoot phase0.Root
+	// committees caches the beacon committees of recent epochs, by slot and committee index.
+	committees map[phase0.Epoch]map[phase0.Slot]map[phase0.CommitteeIndex][]phase0.ValidatorIndex
+
+	HeadRate prometheus.GaugeVec
+	Rate     prometheus.HistogramVec
+}
+
+const (
+	NameParticipation = "participation"
+
+	kindSyncAggregate = "sync_aggregate"
+	kindAttestation   = "attestation"
+)
+
+func (p *Participation) Name() string {
+	return NameParticipation
+}
+
+// NewParticipation returns a new Participation instance.
+func NewParticipation(beac beacon.Node, log logrus.FieldLogger, namespace string, constLabels map[string]string) Participation {
+	constLabels["module"] = NameParticipation
+
+	namespace += "_participation"
+
+	return Participation{
+		beacon:     beac,
+		log:        log.WithField("module", NameParticipation),
+		mu:         &sync.Mutex{},
+		committees: make(map[phase0.Epoch]map[phase0.Slot]map[phase0.CommitteeIndex][]phase0.ValidatorIndex),
+		HeadRate: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "head_rate",
+				Help:        "The participation rate in the most recent head block. Attestation participation is for the slot before the block.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"kind",
+			},
+		),
+		Rate: *prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   namespace,
+				Name:        "rate",
+				Help:        "The participation rates in head blocks. Attestation participation is for the slot before the block.",
+				ConstLabels: constLabels,
+				Buckets:     []float64{0.5, 0.6, 0.7, 0.8, 0.85, 0.9, 0.925, 0.95, 0.975, 0.99, 1},
+			},
+			[]string{
+				"kind",
+			},
+		),
+	}
+}
+
+// Start registers the job with the beacon node.
+func (p *Participation) Start(ctx context.Context) {
+	p.beacon.OnHead(ctx, func(ctx context.Context, event *v1.HeadEvent) error {
+		go p.observeHead(ctx, event)
+
+		return nil
+	})
+}
+
+func (p *Participation) observeHead(ctx context.Context, event *v1.HeadEvent) {
+	// Head blocks received while syncing are historical.
+	if p.beacon.Status().Syncing() {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.lastRoot == event.Block {
+		return
+	}
+
+	p.lastRoot = event.Block
+
+	root := event.Block.String()
+
+	block, err := p.beacon.FetchBlock(ctx, root)
+	if err != nil {
+		p.log.WithError(err).WithField("block", root).Warn("Failed to fetch head block")
+
+		return
+	}
+
+	if block.Version >= eth2spec.DataVersionAltair {
+		if err := p.observeSyncAggregate(block); err != nil {
+			p.log.WithError(err).WithField("block", root).Warn("Failed to observe sync aggregate participation")
+		}
+	}
+
+	if event.Slot == 0 {
+		return
+	}
+
+	if err := p.observeAttestations(ctx, block, event.Slot-1); err != nil {
+		p.log.WithError(err).WithField("block", root).Warn("Failed to observe attestation participation")
+	}
+}
+
+func (p *Participation) observeSyncAggregate(block *eth2spec.VersionedSignedBeaconBlock) error {
+	aggregate, err := block.SyncAggregate()
+	if err != nil {
+		return err
+	}
+
+	size := aggregate.SyncCommitteeBits.Len()
+	if size == 0 {
+		return errors.New("empty sync committee bits")
+	}
+
+	p.observeRate(kindSyncAggregate, float64(aggregate.SyncCommitteeBits.Count())/float64(size))
+
+	return nil
+}
+
+// observeAttestations computes the share of the slot's committee members whose attestation is
+// included in the block. Validators covered by several aggregates are only counted once.
+func (p *Participation) observeAttestations(ctx context.Context, block *eth2spec.VersionedSignedBeaconBlock, slot phase0.Slot) error {
+	committees, err := p.slotCommittees(ctx, slot)
+	if err != nil {
+		return err
+	}
+
+	expected := 0
+	for _, committee := range committees {
+		expected += len(committee)
+	}
+
+	if expected == 0 {
+		return fmt.Errorf("no committees for slot %d", slot)
+	}
+
+	attestations, err := block.Attestations()
+	if err != nil {
+		return err
+	}
+
+	attested, err := attestedValidators(committees, attestations, slot)
+	if err != nil {
+		return err
+	}
+
+	p.observeRate(kindAttestation, float64(len(attested))/float64(expected))
+
+	return nil
+}
+
+// attestedValidators returns the committee members of the slot whose vote is included in one of the
+// attestations. Attestations for other slots are ignored.
+func attestedValidators(committees map[phase0.CommitteeIndex][]phase0.ValidatorIndex, attestations []*eth2spec.VersionedAttestation, slot phase0.Slot) (map[phase0.ValidatorIndex]struct{}, error) {
+	attested := make(map[phase0.ValidatorIndex]struct{})
+
+	for _, attestation := range attestations {
+		data, err := attestation.Data()
+		if err != nil {
+			return nil, err
+		}
+
+		if data.Slot != slot {
+			continue
+		}
+
+		bits, err := attestation.AggregationBits()
+		if err != nil {
+			return nil, err
+		}
+
+		// From Electra onwards an aggregate covers every committee in the committee bits, with
+		// the aggregation bits of the committees concatenated.
+		indices := []phase0.CommitteeIndex{data.Index}
+
+		if committeeBits, err := attestation.CommitteeBits(); err == nil {
+			indices = indices[:0]
+
+			for _, index := range committeeBits.BitIndices() {
+				indices = append(indices, phase0.CommitteeIndex(index))
+			}
+		}
+
+		offset := uint64(0)
+
+		for _, index := range indices {
+			committee := committees[index]
+
+			for position, validator := range committee {
+				if offset+uint64(position) < bits.Len() && bits.BitAt(offset+uint64(position)) {
+					attested[validator] = struct{}{}
+				}
+			}
+
+			offset += uint64(len(committee))
+		}
+	}
+
+	return attested, nil
+}
+
+func (p *Participation) observeRate(kind string, rate float64) {
+	p.HeadRate.WithLabelValues(kind).Set(rate)
+	p.Rate.WithLabelValues(kind).Observe(rate)
+}
+
+// slotCommittees returns the beacon committees of the slot, fetching the committees of its epoch
+// from the head state once.
+func (p *Participation) slotCommittees(ctx context.Context, slot phase0.Slot) (map[phase0.CommitteeIndex][]phase0.ValidatorIndex, error) {
+	spec, err := p.beacon.Spec()
+	if err != nil {
+		return nil, err
+	}
+
+	if spec.SlotsPerEpoch == 0 {
+		return nil, errors.New("slots per epoch is zero")
+	}
+
+	epoch := phase0.Epoch(uint64(slot) / uint64(spec.SlotsPerEpoch))
+
+	if cached, ok := p.committees[epoch]; ok {
+		return cached[slot], nil
+	}
+
+	committees, err := p.beacon.FetchBeaconCommittees(ctx, "head", &epoch)
+	if err != nil {
+		return nil, err
+	}
+
+	bySlot := make(map[phase0.Slot]map[phase0.CommitteeIndex][]phase0.ValidatorIndex)
+
+	for _, committee := range committees {
+		if bySlot[committee.Slot] == nil {
+			bySlot[committee.Slot] = make(map[phase0.CommitteeIndex][]phase0.ValidatorIndex)
+		}
+
+		bySlot[committee.Slot][committee.Index] = committee.Validators
+	}
+
+	// Only the current and previous epoch are needed.
+	for cached := range p.committees {
+		if cached+1 < epoch {
+			delete(p.committees, cached)
+		}
+	}
+
+	p.committees[epoch] = bySlot
+
+	return bySlot[slot], nil
+}
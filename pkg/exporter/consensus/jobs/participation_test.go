@@ -0,0 +1,130 @@
+package jobs
+
+import (
+	"reflect"
+	"testing"
+
+	eth2spec "github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// testBitlist returns an SSZ bitlist of the given length with the given bits set.
+func testBitlist(length int, set ...int) []byte {
+	bits := make([]byte, length/8+1)
+
+	for _, i := range set {
+		bits[i/8] |= 1 << (i % 8)
+	}
+
+	bits[length/8] |= 1 << (length % 8)
+
+	return bits
+}
+
+// testBitvector64 returns an SSZ bitvector of 64 bits with the given bits set.
+func testBitvector64(set ...int) []byte {
+	bits := make([]byte, 8)
+
+	for _, i := range set {
+		bits[i/8] |= 1 << (i % 8)
+	}
+
+	return bits
+}
+
+func testDenebAttestation(slot phase0.Slot, index phase0.CommitteeIndex, bits []byte) *eth2spec.VersionedAttestation {
+	return &eth2spec.VersionedAttestation{
+		Version: eth2spec.DataVersionDeneb,
+		Deneb: &phase0.Attestation{
+			AggregationBits: bits,
+			Data:            &phase0.AttestationData{Slot: slot, Index: index},
+		},
+	}
+}
+
+func testElectraAttestation(slot phase0.Slot, committeeBits, bits []byte) *eth2spec.VersionedAttestation {
+	return &eth2spec.VersionedAttestation{
+		Version: eth2spec.DataVersionElectra,
+		Electra: &electra.Attestation{
+			AggregationBits: bits,
+			Data:            &phase0.AttestationData{Slot: slot},
+			CommitteeBits:   committeeBits,
+		},
+	}
+}
+
+func TestAttestedValidators(t *testing.T) {
+	committees := map[phase0.CommitteeIndex][]phase0.ValidatorIndex{
+		0: {10, 11, 12},
+		1: {20, 21},
+		2: {30, 31, 32, 33},
+	}
+
+	tests := []struct {
+		name         string
+		attestations []*eth2spec.VersionedAttestation
+		want         []phase0.ValidatorIndex
+	}{
+		{
+			name:         "pre-electra attestation uses the committee index of the data",
+			attestations: []*eth2spec.VersionedAttestation{testDenebAttestation(5, 1, testBitlist(2, 1))},
+			want:         []phase0.ValidatorIndex{21},
+		},
+		{
+			name:         "attestations for other slots are ignored",
+			attestations: []*eth2spec.VersionedAttestation{testDenebAttestation(4, 0, testBitlist(3, 0, 1, 2))},
+			want:         []phase0.ValidatorIndex{},
+		},
+		{
+			name: "electra aggregation bits are offset by the preceding committees",
+			attestations: []*eth2spec.VersionedAttestation{
+				// Committees 0 and 2: bits 0-2 cover committee 0 and bits 3-6 committee 2.
+				testElectraAttestation(5, testBitvector64(0, 2), testBitlist(7, 2, 3, 6)),
+			},
+			want: []phase0.ValidatorIndex{12, 30, 33},
+		},
+		{
+			name: "electra committee bits skip unset committees",
+			attestations: []*eth2spec.VersionedAttestation{
+				// Committees 1 and 2: bits 0-1 cover committee 1 and bits 2-5 committee 2.
+				testElectraAttestation(5, testBitvector64(1, 2), testBitlist(6, 0, 2)),
+			},
+			want: []phase0.ValidatorIndex{20, 30},
+		},
+		{
+			name: "pre-electra validators in several aggregates are counted once",
+			attestations: []*eth2spec.VersionedAttestation{
+				testDenebAttestation(5, 0, testBitlist(3, 0, 1)),
+				testDenebAttestation(5, 0, testBitlist(3, 1, 2)),
+			},
+			want: []phase0.ValidatorIndex{10, 11, 12},
+		},
+		{
+			name: "electra validators in several aggregates are counted once",
+			attestations: []*eth2spec.VersionedAttestation{
+				testElectraAttestation(5, testBitvector64(0, 1), testBitlist(5, 0, 3)),
+				testElectraAttestation(5, testBitvector64(1), testBitlist(2, 0, 1)),
+			},
+			want: []phase0.ValidatorIndex{10, 20, 21},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attested, err := attestedValidators(committees, tt.attestations, 5)
+			if err != nil {
+				t.Fatalf("attestedValidators() error = %v", err)
+			}
+
+			want := make(map[phase0.ValidatorIndex]struct{}, len(tt.want))
+			for _, validator := range tt.want {
+				want[validator] = struct{}{}
+			}
+
+			if !reflect.DeepEqual(attested, want) {
+				t.Errorf("attestedValidators() = %v, want %v", attested, want)
+			}
+		})
+	}
+}
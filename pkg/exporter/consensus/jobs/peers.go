@@ -0,0 +1,162 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/ethpandaops/beacon/pkg/beacon/api/types"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// PeersConfig configures the consensus peers job.
+type PeersConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// Peers exposes the peers of the beacon node broken down by state, direction and client.
+type Peers struct {
+	beacon beacon.Node
+	api    api.ConsensusClient
+	log    logrus.FieldLogger
+
+	once *sync.Once
+
+	// agents caches the agent of peers that were looked up individually.
+	agents map[string]string
+
+	ByClient prometheus.GaugeVec
+}
+
+const (
+	NamePeers = "peers"
+
+	// maxPeerLookups limits the number of individual peer requests per tick.
+	maxPeerLookups = 50
+)
+
+func (p *Peers) Name() string {
+	return NamePeers
+}
+
+// NewPeers returns a new Peers instance.
+func NewPeers(beac beacon.Node, consensusAPI api.ConsensusClient, log logrus.FieldLogger, namespace string, constLabels map[string]string) Peers {
+	constLabels["module"] = NamePeers
+
+	namespace += "_peers"
+
+	return Peers{
+		beacon: beac,
+		api:    consensusAPI,
+		log:    log.WithField("module", NamePeers),
+		once:   &sync.Once{},
+		agents: make(map[string]string),
+		ByClient: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "by_client",
+				Help:        "The number of peers of the beacon node by state, direction and client implementation.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"state",
+				"direction",
+				"client",
+			},
+		),
+	}
+}
+
+// Start registers the job with the beacon node. Polling starts once the node is ready.
+func (p *Peers) Start(ctx context.Context) {
+	p.beacon.OnReady(ctx, func(ctx context.Context, event *beacon.ReadyEvent) error {
+		p.once.Do(func() {
+			go p.run(ctx)
+		})
+
+		return nil
+	})
+}
+
+func (p *Peers) run(ctx context.Context) {
+	p.tick(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second * 15):
+			p.tick(ctx)
+		}
+	}
+}
+
+func (p *Peers) tick(ctx context.Context) {
+	// The peers are fetched with the exporter client, which carries the TLS settings of the node.
+	peers, err := p.api.Peers(ctx)
+	if err != nil {
+		p.log.WithError(err).Error("Failed to get peers")
+
+		return
+	}
+
+	type key struct {
+		state     string
+		direction string
+		client    string
+	}
+
+	counts := make(map[key]int)
+	current := make(map[string]bool)
+	lookups := 0
+
+	for _, peer := range peers {
+		current[peer.PeerID] = true
+
+		agent := peer.Agent
+
+		if agent == "" {
+			cached, ok := p.agents[peer.PeerID]
+			if !ok && lookups < maxPeerLookups && peer.State == "connected" {
+				lookups++
+
+				// Failed lookups are not cached, so that they are retried on the next tick.
+				if looked, err := p.lookupAgent(ctx, peer.PeerID); err == nil {
+					cached = looked
+					p.agents[peer.PeerID] = cached
+				}
+			}
+
+			agent = cached
+		}
+
+		counts[key{state: peer.State, direction: peer.Direction, client: string(types.AgentFromString(agent))}]++
+	}
+
+	// Forget peers that are no longer known to the node.
+	for id := range p.agents {
+		if !current[id] {
+			delete(p.agents, id)
+		}
+	}
+
+	p.ByClient.Reset()
+
+	for k, count := range counts {
+		p.ByClient.WithLabelValues(k.state, k.direction, k.client).Set(float64(count))
+	}
+}
+
+func (p *Peers) lookupAgent(ctx context.Context, peerID string) (string, error) {
+	peer, err := p.api.Peer(ctx, peerID)
+	if err != nil {
+		p.log.WithError(err).WithField("peer_id", peerID).Debug("Failed to get peer")
+
+		return "", err
+	}
+
+	return peer.Agent, nil
+}
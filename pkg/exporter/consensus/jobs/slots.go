@@ -0,0 +1,362 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api"
+	"github.com/ethpandaops/ethwallclock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// SlotTrackerConfig configures the missed slot and orphaned block tracker.
+type SlotTrackerConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Epochs is the number of epochs the rolling missed slot ratio is computed over.
+	Epochs uint64 `yaml:"epochs"`
+}
+
+// SlotTrackerTopics are the event stream topics the slot tracker depends on.
+var SlotTrackerTopics = []string{"block", "chain_reorg", "finalized_checkpoint"}
+
+// SlotTracker compares every slot against the blocks received on the event stream.
+type SlotTracker struct {
+	beacon beacon.Node
+	api    api.ConsensusClient
+	log    logrus.FieldLogger
+
+	windowEpochs uint64
+
+	mu *sync.Mutex
+	// blocks holds the block roots seen on the event stream per slot until the slot is finalized.
+	blocks map[phase0.Slot]map[phase0.Root]struct{}
+	// window holds whether each of the most recent slots was missed.
+	window []bool
+	// pending holds the slots waiting to be checked, in order. Slots whose check failed stay at
+	// the front to be retried.
+	pending []phase0.Slot
+	// wake signals the checking goroutine that slots are pending.
+	wake chan struct{}
+
+	Missed          prometheus.Counter
+	Proposed        prometheus.Counter
+	Orphaned        prometheus.Counter
+	ChainReorgs     prometheus.Counter
+	ChainReorgDepth prometheus.Histogram
+	MissedRatio     prometheus.Gauge
+}
+
+const (
+	NameSlotTracker = "slot_tracker"
+
+	defaultSlotTrackerEpochs = 8
+	defaultSlotsPerEpoch     = 32
+
+	// maxTrackedSlots bounds the number of slots kept while waiting for finalization.
+	maxTrackedSlots = 4096
+	// maxPendingSlots bounds the number of slots waiting to be checked while the beacon node fails.
+	maxPendingSlots = 64
+)
+
+func (s *SlotTracker) Name() string {
+	return NameSlotTracker
+}
+
+// NewSlotTracker returns a new SlotTracker instance.
+func NewSlotTracker(beac beacon.Node, consensusAPI api.ConsensusClient, log logrus.FieldLogger, namespace string, constLabels map[string]string, config SlotTrackerConfig) SlotTracker {
+	constLabels["module"] = NameSlotTracker
+
+	namespace += "_slots"
+
+	epochs := config.Epochs
+	if epochs == 0 {
+		epochs = defaultSlotTrackerEpochs
+	}
+
+	return SlotTracker{
+		beacon:       beac,
+		api:          consensusAPI,
+		log:          log.WithField("module", NameSlotTracker),
+		windowEpochs: epochs,
+		mu:           &sync.Mutex{},
+		blocks:       make(map[phase0.Slot]map[phase0.Root]struct{}),
+		wake:         make(chan struct{}, 1),
+		Missed: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "missed_total",
+				Help:        "The number of slots for which no block was seen on the event stream and the beacon node has no block header.",
+				ConstLabels: constLabels,
+			},
+		),
+		Proposed: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "proposed_total",
+				Help:        "The number of slots for which a block was seen on the event stream or the beacon node has a block header.",
+				ConstLabels: constLabels,
+			},
+		),
+		Orphaned: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "orphaned_blocks_total",
+				Help:        "The number of blocks seen on the event stream that were not canonical once finalized.",
+				ConstLabels: constLabels,
+			},
+		),
+		ChainReorgs: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "chain_reorgs_total",
+				Help:        "The number of chain reorg events received.",
+				ConstLabels: constLabels,
+			},
+		),
+		ChainReorgDepth: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace:   namespace,
+				Name:        "chain_reorg_depth",
+				Help:        "The depth of chain reorg events (in slots).",
+				ConstLabels: constLabels,
+				Buckets:     []float64{1, 2, 3, 4, 6, 8, 16, 32, 64},
+			},
+		),
+		MissedRatio: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "missed_ratio",
+				Help:        fmt.Sprintf("The ratio of missed slots over the last %d epochs.", epochs),
+				ConstLabels: constLabels,
+			},
+		),
+	}
+}
+
+// Start registers the job with the beacon node.
+func (s *SlotTracker) Start(ctx context.Context) {
+	s.beacon.OnBlock(ctx, s.handleBlock)
+	s.beacon.OnChainReOrg(ctx, s.handleChainReorg)
+	s.beacon.OnFinalizedCheckpoint(ctx, s.handleFinalizedCheckpoint)
+
+	s.beacon.OnReady(ctx, func(ctx context.Context, event *beacon.ReadyEvent) error {
+		go s.checkPending(ctx)
+
+		s.beacon.Wallclock().OnSlotChanged(func(slot ethwallclock.Slot) {
+			// Give late blocks a full slot to arrive before the slot is checked.
+			if slot.Number() < 2 {
+				return
+			}
+
+			s.enqueue(phase0.Slot(slot.Number() - 2))
+		})
+
+		return nil
+	})
+}
+
+func (s *SlotTracker) enqueue(slot phase0.Slot) {
+	if s.beacon.Status().Syncing() {
+		return
+	}
+
+	s.mu.Lock()
+
+	s.pending = append(s.pending, slot)
+	if len(s.pending) > maxPendingSlots {
+		s.log.WithField("slots", len(s.pending)-maxPendingSlots).Warn("Too many slots waiting to be checked, dropping the oldest")
+
+		s.pending = s.pending[len(s.pending)-maxPendingSlots:]
+	}
+
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// checkPending checks the pending slots in order from a single goroutine, so that s.mu is only
+// held briefly and the block event handler is never blocked on beacon API requests. When a check
+// fails, the remaining slots wait for the next slot to be retried.
+func (s *SlotTracker) checkPending(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		}
+
+		for {
+			s.mu.Lock()
+
+			if len(s.pending) == 0 {
+				s.mu.Unlock()
+
+				break
+			}
+
+			slot := s.pending[0]
+
+			s.mu.Unlock()
+
+			if err := s.checkSlot(ctx, slot); err != nil {
+				s.log.WithError(err).WithField("slot", slot).Warn("Failed to check slot, retrying on the next slot")
+
+				break
+			}
+
+			s.mu.Lock()
+			s.pending = s.pending[1:]
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *SlotTracker) handleBlock(ctx context.Context, event *v1.BlockEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.blocks[event.Slot] == nil {
+		s.blocks[event.Slot] = make(map[phase0.Root]struct{})
+	}
+
+	s.blocks[event.Slot][event.Block] = struct{}{}
+
+	return nil
+}
+
+func (s *SlotTracker) handleChainReorg(ctx context.Context, event *v1.ChainReorgEvent) error {
+	s.ChainReorgs.Inc()
+	s.ChainReorgDepth.Observe(float64(event.Depth))
+
+	return nil
+}
+
+func (s *SlotTracker) checkSlot(ctx context.Context, slot phase0.Slot) error {
+	s.mu.Lock()
+	missed := len(s.blocks[slot]) == 0
+	s.mu.Unlock()
+
+	// The event stream may have been disconnected, so confirm with the beacon node before counting a miss.
+	if missed {
+		header, err := s.api.BlockHeader(ctx, fmt.Sprintf("%d", slot))
+		if err != nil && !errors.Is(err, api.ErrNotFound) {
+			return fmt.Errorf("failed to fetch block header: %w", err)
+		}
+
+		if header != nil && header.Header.Message.Slot == slot {
+			missed = false
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if missed {
+		s.Missed.Inc()
+	} else {
+		s.Proposed.Inc()
+	}
+
+	s.observeWindow(missed)
+
+	for tracked := range s.blocks {
+		if tracked+maxTrackedSlots < slot {
+			delete(s.blocks, tracked)
+		}
+	}
+
+	return nil
+}
+
+func (s *SlotTracker) observeWindow(missed bool) {
+	size := int(s.windowEpochs) * defaultSlotsPerEpoch
+
+	if spec, err := s.beacon.Spec(); err == nil && spec.SlotsPerEpoch > 0 {
+		size = int(s.windowEpochs) * int(spec.SlotsPerEpoch)
+	}
+
+	s.window = append(s.window, missed)
+	if len(s.window) > size {
+		s.window = s.window[len(s.window)-size:]
+	}
+
+	count := 0
+
+	for _, m := range s.window {
+		if m {
+			count++
+		}
+	}
+
+	s.MissedRatio.Set(float64(count) / float64(len(s.window)))
+}
+
+func (s *SlotTracker) handleFinalizedCheckpoint(ctx context.Context, event *v1.FinalizedCheckpointEvent) error {
+	spec, err := s.beacon.Spec()
+	if err != nil {
+		return err
+	}
+
+	finalizedSlot := phase0.Slot(uint64(event.Epoch) * uint64(spec.SlotsPerEpoch))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	finalized := make(map[phase0.Slot]map[phase0.Root]struct{})
+
+	for slot, roots := range s.blocks {
+		if slot > finalizedSlot {
+			continue
+		}
+
+		finalized[slot] = roots
+
+		delete(s.blocks, slot)
+	}
+
+	// The canonical blocks are fetched outside of the event handler, which would otherwise stall
+	// the event stream for one request per tracked slot.
+	go s.checkOrphaned(ctx, finalized)
+
+	return nil
+}
+
+// checkOrphaned counts the blocks of finalized slots that are not canonical.
+func (s *SlotTracker) checkOrphaned(ctx context.Context, finalized map[phase0.Slot]map[phase0.Root]struct{}) {
+	for slot, roots := range finalized {
+		header, err := s.api.BlockHeader(ctx, fmt.Sprintf("%d", slot))
+		if err != nil && !errors.Is(err, api.ErrNotFound) {
+			s.log.WithError(err).WithField("slot", slot).Warn("Failed to fetch finalized block header")
+
+			// Retry on the next finalized checkpoint.
+			s.mu.Lock()
+
+			for root := range roots {
+				if s.blocks[slot] == nil {
+					s.blocks[slot] = make(map[phase0.Root]struct{})
+				}
+
+				s.blocks[slot][root] = struct{}{}
+			}
+
+			s.mu.Unlock()
+
+			continue
+		}
+
+		for root := range roots {
+			if header == nil || header.Header.Message.Slot != slot || header.Root != root {
+				s.Orphaned.Inc()
+			}
+		}
+	}
+}
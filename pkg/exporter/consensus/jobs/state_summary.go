@@ -0,0 +1,334 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api"
+	"github.com/ethpandaops/ethwallclock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// StateSummaryConfig configures the chain-wide beacon state summary.
+type StateSummaryConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Epochs is how often the summary is refreshed, in epochs. Fetching every validator is
+	// expensive on large networks (hundreds of MB on mainnet), so it defaults to 32 epochs.
+	Epochs uint64 `yaml:"epochs"`
+}
+
+// StateSummary exposes chain-wide validator counts, queue lengths and churn limits from the head state.
+type StateSummary struct {
+	beacon beacon.Node
+	api    api.ConsensusClient
+	log    logrus.FieldLogger
+
+	epochs uint64
+	mu     *sync.Mutex
+
+	Validators                    prometheus.GaugeVec
+	ActivationQueue               prometheus.Gauge
+	ExitQueue                     prometheus.Gauge
+	TotalActiveBalance            prometheus.Gauge
+	ChurnLimit                    prometheus.Gauge
+	ChurnLimitGwei                prometheus.GaugeVec
+	PendingDeposits               prometheus.Gauge
+	PendingDepositsGwei           prometheus.Gauge
+	PendingConsolidations         prometheus.Gauge
+	PendingPartialWithdrawals     prometheus.Gauge
+	PendingPartialWithdrawalsGwei prometheus.Gauge
+}
+
+const (
+	NameStateSummary = "state_summary"
+
+	defaultStateSummaryEpochs = 32
+
+	defaultMinPerEpochChurnLimit               = 4
+	defaultChurnLimitQuotient                  = 65536
+	defaultMaxPerEpochActivationChurnLimit     = 8
+	defaultMinPerEpochChurnLimitElectra        = 128_000_000_000
+	defaultMaxPerEpochActivationExitChurnLimit = 256_000_000_000
+	defaultEffectiveBalanceIncrement           = 1_000_000_000
+)
+
+func (s *StateSummary) Name() string {
+	return NameStateSummary
+}
+
+// NewStateSummary returns a new StateSummary instance.
+func NewStateSummary(beac beacon.Node, consensusAPI api.ConsensusClient, log logrus.FieldLogger, namespace string, constLabels map[string]string, config StateSummaryConfig) StateSummary {
+	constLabels["module"] = NameStateSummary
+
+	namespace += "_state"
+
+	epochs := config.Epochs
+	if epochs == 0 {
+		epochs = defaultStateSummaryEpochs
+	}
+
+	return StateSummary{
+		beacon: beac,
+		api:    consensusAPI,
+		log:    log.WithField("module", NameStateSummary),
+		epochs: epochs,
+		mu:     &sync.Mutex{},
+		Validators: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "validators",
+				Help:        "The number of validators in the head state by status.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"status",
+			},
+		),
+		ActivationQueue: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "activation_queue",
+				Help:        "The number of validators that are eligible and waiting for activation.",
+				ConstLabels: constLabels,
+			},
+		),
+		ExitQueue: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "exit_queue",
+				Help:        "The number of active validators that are exiting, including slashed validators.",
+				ConstLabels: constLabels,
+			},
+		),
+		TotalActiveBalance: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "total_active_balance_gwei",
+				Help:        "The sum of the effective balances of all active validators (in gwei).",
+				ConstLabels: constLabels,
+			},
+		),
+		ChurnLimit: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "churn_limit",
+				Help:        "The number of validators that can be activated per epoch (before Electra).",
+				ConstLabels: constLabels,
+			},
+		),
+		ChurnLimitGwei: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "churn_limit_gwei",
+				Help:        "The balance churn limits per epoch (in gwei, Electra and later).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"kind",
+			},
+		),
+		PendingDeposits: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "pending_deposits",
+				Help:        "The number of pending deposits in the head state.",
+				ConstLabels: constLabels,
+			},
+		),
+		PendingDepositsGwei: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "pending_deposits_gwei",
+				Help:        "The sum of the pending deposits in the head state (in gwei).",
+				ConstLabels: constLabels,
+			},
+		),
+		PendingConsolidations: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "pending_consolidations",
+				Help:        "The number of pending consolidations in the head state.",
+				ConstLabels: constLabels,
+			},
+		),
+		PendingPartialWithdrawals: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "pending_partial_withdrawals",
+				Help:        "The number of pending partial withdrawals in the head state.",
+				ConstLabels: constLabels,
+			},
+		),
+		PendingPartialWithdrawalsGwei: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "pending_partial_withdrawals_gwei",
+				Help:        "The sum of the pending partial withdrawals in the head state (in gwei).",
+				ConstLabels: constLabels,
+			},
+		),
+	}
+}
+
+// Start registers the job with the beacon node.
+func (s *StateSummary) Start(ctx context.Context) {
+	s.beacon.OnReady(ctx, func(ctx context.Context, event *beacon.ReadyEvent) error {
+		s.beacon.Wallclock().OnEpochChanged(func(epoch ethwallclock.Epoch) {
+			if epoch.Number()%s.epochs != 0 {
+				return
+			}
+
+			go s.tick(ctx)
+		})
+
+		go s.tick(ctx)
+
+		return nil
+	})
+}
+
+func (s *StateSummary) tick(ctx context.Context) {
+	// Skip the refresh if the previous one is still fetching the validators.
+	if !s.mu.TryLock() {
+		return
+	}
+
+	defer s.mu.Unlock()
+
+	if err := s.observeValidators(ctx); err != nil {
+		s.log.WithError(err).Error("Failed to observe validators")
+	}
+
+	if err := s.observePendingQueues(ctx); err != nil {
+		s.log.WithError(err).Error("Failed to observe pending queues")
+	}
+}
+
+func (s *StateSummary) observeValidators(ctx context.Context) error {
+	spec, err := s.beacon.Spec()
+	if err != nil {
+		return err
+	}
+
+	validators, err := s.beacon.FetchValidators(ctx, "head", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[v1.ValidatorState]int)
+
+	var (
+		active        uint64
+		activeBalance phase0.Gwei
+	)
+
+	for _, validator := range validators {
+		counts[validator.Status]++
+
+		if validator.Status.IsActive() && validator.Validator != nil {
+			active++
+			activeBalance += validator.Validator.EffectiveBalance
+		}
+	}
+
+	s.Validators.Reset()
+
+	for status, count := range counts {
+		s.Validators.WithLabelValues(status.String()).Set(float64(count))
+	}
+
+	s.ActivationQueue.Set(float64(counts[v1.ValidatorStatePendingQueued]))
+	s.ExitQueue.Set(float64(counts[v1.ValidatorStateActiveExiting] + counts[v1.ValidatorStateActiveSlashed]))
+	s.TotalActiveBalance.Set(float64(activeBalance))
+
+	epoch := s.beacon.Wallclock().Epochs().Current()
+
+	s.observeChurn(spec, phase0.Epoch(epoch.Number()), active, uint64(activeBalance))
+
+	return nil
+}
+
+// observeChurn computes the churn limits following the consensus specs: a validator count
+// before Electra and balance based limits from Electra onwards.
+func (s *StateSummary) observeChurn(spec *state.Spec, epoch phase0.Epoch, active, activeBalance uint64) {
+	quotient := specUint(spec, "CHURN_LIMIT_QUOTIENT", defaultChurnLimitQuotient)
+	if quotient == 0 {
+		return
+	}
+
+	electra, err := spec.ForkEpochs.GetByName("electra")
+	if err != nil || !electra.Active(epoch) {
+		churn := max(specUint(spec, "MIN_PER_EPOCH_CHURN_LIMIT", defaultMinPerEpochChurnLimit), active/quotient)
+
+		// Deneb caps the activation churn.
+		if deneb, err := spec.ForkEpochs.GetByName("deneb"); err == nil && deneb.Active(epoch) {
+			churn = min(churn, specUint(spec, "MAX_PER_EPOCH_ACTIVATION_CHURN_LIMIT", defaultMaxPerEpochActivationChurnLimit))
+		}
+
+		s.ChurnLimit.Set(float64(churn))
+		s.ChurnLimitGwei.Reset()
+
+		return
+	}
+
+	increment := specUint(spec, "EFFECTIVE_BALANCE_INCREMENT", defaultEffectiveBalanceIncrement)
+
+	balanceChurn := max(specUint(spec, "MIN_PER_EPOCH_CHURN_LIMIT_ELECTRA", defaultMinPerEpochChurnLimitElectra), activeBalance/quotient)
+	if increment > 0 {
+		balanceChurn -= balanceChurn % increment
+	}
+
+	activationExitChurn := min(specUint(spec, "MAX_PER_EPOCH_ACTIVATION_EXIT_CHURN_LIMIT", defaultMaxPerEpochActivationExitChurnLimit), balanceChurn)
+
+	s.ChurnLimitGwei.WithLabelValues("balance").Set(float64(balanceChurn))
+	s.ChurnLimitGwei.WithLabelValues("activation_exit").Set(float64(activationExitChurn))
+	s.ChurnLimitGwei.WithLabelValues("consolidation").Set(float64(balanceChurn - activationExitChurn))
+}
+
+func (s *StateSummary) observePendingQueues(ctx context.Context) error {
+	deposits, err := s.api.PendingDeposits(ctx, "head")
+	if err != nil {
+		// The pending queues only exist from Electra onwards.
+		if errors.Is(err, api.ErrNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	var depositsGwei phase0.Gwei
+	for _, deposit := range deposits {
+		depositsGwei += deposit.Amount
+	}
+
+	s.PendingDeposits.Set(float64(len(deposits)))
+	s.PendingDepositsGwei.Set(float64(depositsGwei))
+
+	consolidations, err := s.api.PendingConsolidations(ctx, "head")
+	if err != nil {
+		return err
+	}
+
+	s.PendingConsolidations.Set(float64(len(consolidations)))
+
+	withdrawals, err := s.api.PendingPartialWithdrawals(ctx, "head")
+	if err != nil {
+		return err
+	}
+
+	var withdrawalsGwei phase0.Gwei
+	for _, withdrawal := range withdrawals {
+		withdrawalsGwei += withdrawal.Amount
+	}
+
+	s.PendingPartialWithdrawals.Set(float64(len(withdrawals)))
+	s.PendingPartialWithdrawalsGwei.Set(float64(withdrawalsGwei))
+
+	return nil
+}
@@ -0,0 +1,502 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api/types"
+	"github.com/ethpandaops/ethwallclock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// Validators exposes per-epoch performance metrics for a configured set of validators.
+type Validators struct {
+	beacon beacon.Node
+	api    api.ConsensusClient
+	log    logrus.FieldLogger
+
+	watched *WatchedValidators
+
+	mu                 *sync.Mutex
+	lastProcessedEpoch *phase0.Epoch
+	// completed holds the last epoch each step was observed for, so that failed epochs are retried.
+	completed map[string]phase0.Epoch
+
+	Status                     prometheus.GaugeVec
+	Balance                    prometheus.GaugeVec
+	EffectiveBalance           prometheus.GaugeVec
+	AttestationReward          prometheus.GaugeVec
+	AttestationCorrect         prometheus.GaugeVec
+	AttestationsMissed         prometheus.CounterVec
+	Proposals                  prometheus.CounterVec
+	SyncCommitteeMember        prometheus.GaugeVec
+	SyncCommitteeParticipation prometheus.CounterVec
+	ProcessedEpoch             prometheus.Gauge
+}
+
+const (
+	NameValidators = "validators"
+
+	defaultMinEpochsToInactivityPenalty = 4
+
+	// maxValidatorCatchUpEpochs bounds how many failed epochs a step retries on a tick.
+	maxValidatorCatchUpEpochs = 4
+)
+
+// validatorStep is a part of the validator performance that is observed once per epoch. Steps
+// must not update any counter unless they succeed, so that failed epochs can be retried.
+type validatorStep struct {
+	name string
+	// lag is the number of epochs after which the data of an epoch is final.
+	lag     phase0.Epoch
+	observe func(ctx context.Context, epoch phase0.Epoch) error
+}
+
+func (v *Validators) Name() string {
+	return NameValidators
+}
+
+// Enabled returns true if there is at least one validator to watch.
+func (v *Validators) Enabled() bool {
+	return !v.watched.Empty()
+}
+
+// NewValidators returns a new Validators instance.
+func NewValidators(beac beacon.Node, consensusAPI api.ConsensusClient, log logrus.FieldLogger, namespace string, constLabels map[string]string, watched *WatchedValidators) Validators {
+	constLabels["module"] = NameValidators
+
+	namespace += "_validator"
+
+	log = log.WithField("module", NameValidators)
+
+	v := Validators{
+		beacon:    beac,
+		api:       consensusAPI,
+		log:       log,
+		watched:   watched,
+		mu:        &sync.Mutex{},
+		completed: make(map[string]phase0.Epoch),
+		Status: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "status",
+				Help:        "The status of the validator (1 for the current status).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"index",
+				"name",
+				"status",
+			},
+		),
+		Balance: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "balance_gwei",
+				Help:        "The balance of the validator (in gwei).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"index",
+				"name",
+			},
+		),
+		EffectiveBalance: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "effective_balance_gwei",
+				Help:        "The effective balance of the validator (in gwei).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"index",
+				"name",
+			},
+		),
+		AttestationReward: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "attestation_reward_gwei",
+				Help:        "The attestation reward of the validator in the last processed epoch (in gwei, negative for penalties).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"index",
+				"name",
+				"flag",
+			},
+		),
+		AttestationCorrect: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "attestation_correct",
+				Help:        "Whether the validator's attestation in the last processed epoch was included with a correct vote (1 for correct). Head correctness is not reported during an inactivity leak.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"index",
+				"name",
+				"flag",
+			},
+		),
+		AttestationsMissed: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "attestations_missed_total",
+				Help:        "The number of epochs in which the validator's attestation was not included in time.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"index",
+				"name",
+			},
+		),
+		Proposals: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "proposals_total",
+				Help:        "The number of block proposals by the validator by result (proposed or missed).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"index",
+				"name",
+				"result",
+			},
+		),
+		SyncCommitteeMember: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "sync_committee_member",
+				Help:        "Whether the validator is a member of the sync committee in the last processed epoch (1 for member).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"index",
+				"name",
+			},
+		),
+		SyncCommitteeParticipation: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "sync_committee_participation_total",
+				Help:        "The number of sync committee duties of the validator by result (participated or missed).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"index",
+				"name",
+				"result",
+			},
+		),
+		ProcessedEpoch: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "processed_epoch",
+				Help:        "The most recent epoch for which validator performance was computed.",
+				ConstLabels: constLabels,
+			},
+		),
+	}
+
+	return v
+}
+
+// Start registers the job with the beacon node. Metrics are computed once the node is ready and on every new epoch.
+func (v *Validators) Start(ctx context.Context) {
+	v.beacon.OnReady(ctx, func(ctx context.Context, event *beacon.ReadyEvent) error {
+		v.beacon.Wallclock().OnEpochChanged(func(epoch ethwallclock.Epoch) {
+			v.tick(ctx, phase0.Epoch(epoch.Number()))
+		})
+
+		epoch := v.beacon.Wallclock().Epochs().Current()
+
+		v.tick(ctx, phase0.Epoch(epoch.Number()))
+
+		return nil
+	})
+}
+
+func (v *Validators) tick(ctx context.Context, current phase0.Epoch) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.lastProcessedEpoch != nil && current <= *v.lastProcessedEpoch {
+		return
+	}
+
+	if err := v.updateValidators(ctx); err != nil {
+		v.log.WithError(err).Error("Failed to update validators")
+
+		return
+	}
+
+	steps := []validatorStep{
+		// Attestation rewards for an epoch are only final once the following epoch has ended.
+		{name: "attestation rewards", lag: 2, observe: v.observeAttestations},
+		{name: "proposals", lag: 1, observe: v.observeProposals},
+		{name: "sync committee participation", lag: 1, observe: v.observeSyncCommittee},
+	}
+
+	for _, step := range steps {
+		v.observeStep(ctx, step, current)
+	}
+
+	v.lastProcessedEpoch = &current
+	v.ProcessedEpoch.Set(float64(current))
+}
+
+// observeStep observes the step for every epoch since the last one it succeeded for, up to the
+// latest final epoch.
+func (v *Validators) observeStep(ctx context.Context, step validatorStep, current phase0.Epoch) {
+	if current < step.lag {
+		return
+	}
+
+	target := current - step.lag
+	from := target
+
+	if last, ok := v.completed[step.name]; ok {
+		if last >= target {
+			return
+		}
+
+		from = last + 1
+
+		if target-from >= maxValidatorCatchUpEpochs {
+			from = target - maxValidatorCatchUpEpochs + 1
+		}
+	}
+
+	for epoch := from; epoch <= target; epoch++ {
+		if err := step.observe(ctx, epoch); err != nil {
+			v.log.WithError(err).WithField("epoch", epoch).Errorf("Failed to observe %s, retrying next epoch", step.name)
+
+			return
+		}
+
+		v.completed[step.name] = epoch
+	}
+}
+
+func (v *Validators) updateValidators(ctx context.Context) error {
+	validators, err := v.watched.Resolve(ctx, v.beacon)
+	if err != nil {
+		return err
+	}
+
+	for index, validator := range validators {
+		indexLabel, nameLabel := v.watched.Labels(index)
+
+		v.Status.DeletePartialMatch(prometheus.Labels{"index": indexLabel})
+		v.Status.WithLabelValues(indexLabel, nameLabel, strings.ToLower(validator.Status.String())).Set(1)
+		v.Balance.WithLabelValues(indexLabel, nameLabel).Set(float64(validator.Balance))
+
+		if validator.Validator != nil {
+			v.EffectiveBalance.WithLabelValues(indexLabel, nameLabel).Set(float64(validator.Validator.EffectiveBalance))
+		}
+	}
+
+	return nil
+}
+
+func (v *Validators) observeAttestations(ctx context.Context, epoch phase0.Epoch) error {
+	if len(v.watched.Indices()) == 0 {
+		return nil
+	}
+
+	leaking, err := v.inactivityLeak(ctx, epoch)
+	if err != nil {
+		return err
+	}
+
+	rewards, err := v.api.AttestationRewards(ctx, epoch, v.watched.Indices())
+	if err != nil {
+		return err
+	}
+
+	for _, reward := range rewards.TotalRewards {
+		if !v.watched.Contains(reward.ValidatorIndex) {
+			continue
+		}
+
+		index, name := v.watched.Labels(reward.ValidatorIndex)
+
+		for flag, value := range map[string]int64{
+			"source": reward.Source,
+			"target": reward.Target,
+			"head":   reward.Head,
+		} {
+			v.AttestationReward.WithLabelValues(index, name, flag).Set(float64(value))
+		}
+
+		correct, missed := attestationCorrectness(reward, leaking)
+
+		for _, flag := range []string{"source", "target", "head"} {
+			ok, known := correct[flag]
+			if !known {
+				v.AttestationCorrect.DeleteLabelValues(index, name, flag)
+
+				continue
+			}
+
+			if ok {
+				v.AttestationCorrect.WithLabelValues(index, name, flag).Set(1)
+			} else {
+				v.AttestationCorrect.WithLabelValues(index, name, flag).Set(0)
+			}
+		}
+
+		if missed {
+			v.AttestationsMissed.WithLabelValues(index, name).Inc()
+		}
+	}
+
+	return nil
+}
+
+// inactivityLeak returns true if the chain was in an inactivity leak when the rewards of the epoch
+// were computed, based on the finalized checkpoint of the head state.
+func (v *Validators) inactivityLeak(ctx context.Context, epoch phase0.Epoch) (bool, error) {
+	spec, err := v.beacon.Spec()
+	if err != nil {
+		return false, err
+	}
+
+	checkpoints, err := v.api.FinalityCheckpoints(ctx, "head")
+	if err != nil {
+		return false, err
+	}
+
+	minEpochs := phase0.Epoch(specUint(spec, "MIN_EPOCHS_TO_INACTIVITY_PENALTY", defaultMinEpochsToInactivityPenalty))
+
+	return epoch > checkpoints.Finalized.Epoch+minEpochs, nil
+}
+
+// attestationCorrectness derives the correctness of the source, target and head votes from the
+// attestation rewards. Missed source and target votes are penalised, so a negative value is a miss
+// while zero is not: during an inactivity leak correct votes are not rewarded. Missed head votes are
+// never penalised, so head correctness is unknown (absent from the map) during a leak. The
+// attestation is missed when its source vote was penalised, i.e. it was not included in time.
+func attestationCorrectness(reward types.AttestationReward, leaking bool) (correct map[string]bool, missed bool) {
+	correct = map[string]bool{
+		"source": reward.Source >= 0,
+		"target": reward.Target >= 0,
+	}
+
+	if !leaking {
+		correct["head"] = reward.Head > 0
+	}
+
+	return correct, reward.Source < 0
+}
+
+func (v *Validators) observeProposals(ctx context.Context, epoch phase0.Epoch) error {
+	duties, err := v.beacon.FetchProposerDuties(ctx, epoch)
+	if err != nil {
+		return err
+	}
+
+	// The results are only counted once every duty was checked, so that the epoch can be retried.
+	results := make(map[phase0.ValidatorIndex][]string)
+
+	for _, duty := range duties {
+		if !v.watched.Contains(duty.ValidatorIndex) {
+			continue
+		}
+
+		header, err := v.api.BlockHeader(ctx, fmt.Sprintf("%d", duty.Slot))
+		if err != nil && !errors.Is(err, api.ErrNotFound) {
+			return fmt.Errorf("failed to fetch block header of slot %d: %w", duty.Slot, err)
+		}
+
+		if header != nil && header.Canonical && header.Header.Message.ProposerIndex == duty.ValidatorIndex {
+			results[duty.ValidatorIndex] = append(results[duty.ValidatorIndex], "proposed")
+		} else {
+			results[duty.ValidatorIndex] = append(results[duty.ValidatorIndex], "missed")
+		}
+	}
+
+	for validator, proposals := range results {
+		index, name := v.watched.Labels(validator)
+
+		for _, result := range proposals {
+			v.Proposals.WithLabelValues(index, name, result).Inc()
+		}
+	}
+
+	return nil
+}
+
+func (v *Validators) observeSyncCommittee(ctx context.Context, epoch phase0.Epoch) error {
+	committee, err := v.api.SyncCommittee(ctx, "head", &epoch)
+	if err != nil {
+		return err
+	}
+
+	members := make(map[phase0.ValidatorIndex]bool)
+	for _, index := range committee.Validators {
+		members[index] = true
+	}
+
+	watchedMembers := []phase0.ValidatorIndex{}
+
+	for _, index := range v.watched.Indices() {
+		indexLabel, name := v.watched.Labels(index)
+
+		if members[index] {
+			watchedMembers = append(watchedMembers, index)
+
+			v.SyncCommitteeMember.WithLabelValues(indexLabel, name).Set(1)
+		} else {
+			v.SyncCommitteeMember.WithLabelValues(indexLabel, name).Set(0)
+		}
+	}
+
+	if len(watchedMembers) == 0 {
+		return nil
+	}
+
+	spec, err := v.beacon.Spec()
+	if err != nil {
+		return err
+	}
+
+	start := phase0.Slot(uint64(epoch) * uint64(spec.SlotsPerEpoch))
+
+	// The rewards are only counted once every slot was fetched, so that the epoch can be retried.
+	rewards := []types.SyncCommitteeReward{}
+
+	for slot := start; slot < start+spec.SlotsPerEpoch; slot++ {
+		slotRewards, err := v.api.SyncCommitteeRewards(ctx, fmt.Sprintf("%d", slot), watchedMembers)
+		if err != nil {
+			// Empty slots have no sync aggregate to reward.
+			if errors.Is(err, api.ErrNotFound) {
+				continue
+			}
+
+			return err
+		}
+
+		rewards = append(rewards, slotRewards...)
+	}
+
+	for _, reward := range rewards {
+		index, name := v.watched.Labels(reward.ValidatorIndex)
+
+		if reward.Reward > 0 {
+			v.SyncCommitteeParticipation.WithLabelValues(index, name, "participated").Inc()
+		} else {
+			v.SyncCommitteeParticipation.WithLabelValues(index, name, "missed").Inc()
+		}
+	}
+
+	return nil
+}
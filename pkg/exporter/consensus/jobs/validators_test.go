@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api/types"
+)
+
+func TestAttestationCorrectness(t *testing.T) {
+	tests := []struct {
+		name        string
+		reward      types.AttestationReward
+		leaking     bool
+		wantCorrect map[string]bool
+		wantMissed  bool
+	}{
+		{
+			name:        "correct attestation",
+			reward:      types.AttestationReward{Source: 7000, Target: 13000, Head: 7000},
+			wantCorrect: map[string]bool{"source": true, "target": true, "head": true},
+		},
+		{
+			name:        "wrong head vote",
+			reward:      types.AttestationReward{Source: 7000, Target: 13000},
+			wantCorrect: map[string]bool{"source": true, "target": true, "head": false},
+		},
+		{
+			name:        "missed attestation",
+			reward:      types.AttestationReward{Source: -7000, Target: -13000},
+			wantCorrect: map[string]bool{"source": false, "target": false, "head": false},
+			wantMissed:  true,
+		},
+		{
+			name:        "correct attestation during an inactivity leak",
+			reward:      types.AttestationReward{Inactivity: 0},
+			leaking:     true,
+			wantCorrect: map[string]bool{"source": true, "target": true},
+		},
+		{
+			name:        "missed attestation during an inactivity leak",
+			reward:      types.AttestationReward{Source: -7000, Target: -13000, Inactivity: -20000},
+			leaking:     true,
+			wantCorrect: map[string]bool{"source": false, "target": false},
+			wantMissed:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			correct, missed := attestationCorrectness(tt.reward, tt.leaking)
+
+			if !reflect.DeepEqual(correct, tt.wantCorrect) {
+				t.Errorf("attestationCorrectness() correct = %v, want %v", correct, tt.wantCorrect)
+			}
+
+			if missed != tt.wantMissed {
+				t.Errorf("attestationCorrectness() missed = %v, want %v", missed, tt.wantMissed)
+			}
+		})
+	}
+}
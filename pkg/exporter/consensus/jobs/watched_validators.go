@@ -0,0 +1,172 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/sirupsen/logrus"
+)
+
+// ValidatorConfig identifies a validator to watch, either by index or public key.
+type ValidatorConfig struct {
+	Index  *uint64 `yaml:"index"`
+	Pubkey string  `yaml:"pubkey"`
+	Name   string  `yaml:"name"`
+}
+
+const (
+	// MaxWatchedValidators bounds the label cardinality of the per-validator metrics.
+	MaxWatchedValidators = 1000
+)
+
+// WatchedValidators is the set of configured validators. A single instance is shared by the
+// per-validator jobs so that the validators are only fetched once per epoch.
+type WatchedValidators struct {
+	indices []phase0.ValidatorIndex
+	pubkeys []phase0.BLSPubKey
+	names   map[string]string
+
+	mu *sync.RWMutex
+	// resolved maps the index of every watched validator known to the beacon node to its name.
+	resolved map[phase0.ValidatorIndex]string
+	// validators and resolvedEpoch hold the result of the last Resolve.
+	validators    map[phase0.ValidatorIndex]*v1.Validator
+	resolvedEpoch *uint64
+}
+
+// NewWatchedValidators returns the set of configured validators.
+func NewWatchedValidators(log logrus.FieldLogger, validators []ValidatorConfig) *WatchedValidators {
+	if len(validators) > MaxWatchedValidators {
+		log.WithField("configured", len(validators)).Warnf("Too many validators configured, only watching the first %d", MaxWatchedValidators)
+
+		validators = validators[:MaxWatchedValidators]
+	}
+
+	w := &WatchedValidators{
+		names:    make(map[string]string),
+		mu:       &sync.RWMutex{},
+		resolved: make(map[phase0.ValidatorIndex]string),
+	}
+
+	for _, validator := range validators {
+		switch {
+		case validator.Index != nil:
+			index := phase0.ValidatorIndex(*validator.Index)
+
+			w.indices = append(w.indices, index)
+			w.names[fmt.Sprintf("%d", index)] = validator.Name
+		case validator.Pubkey != "":
+			pubkey, err := parsePubkey(validator.Pubkey)
+			if err != nil {
+				log.WithError(err).WithField("pubkey", validator.Pubkey).Error("Invalid validator pubkey, skipping")
+
+				continue
+			}
+
+			w.pubkeys = append(w.pubkeys, pubkey)
+			w.names[pubkey.String()] = validator.Name
+		default:
+			log.WithField("name", validator.Name).Error("Validator has neither an index nor a pubkey, skipping")
+		}
+	}
+
+	return w
+}
+
+func parsePubkey(value string) (phase0.BLSPubKey, error) {
+	raw, err := hexutil.Decode(value)
+	if err != nil {
+		return phase0.BLSPubKey{}, err
+	}
+
+	if len(raw) != len(phase0.BLSPubKey{}) {
+		return phase0.BLSPubKey{}, fmt.Errorf("pubkey must be %d bytes", len(phase0.BLSPubKey{}))
+	}
+
+	var pubkey phase0.BLSPubKey
+
+	copy(pubkey[:], raw)
+
+	return pubkey, nil
+}
+
+// Empty returns true if no valid validators are configured.
+func (w *WatchedValidators) Empty() bool {
+	return len(w.indices)+len(w.pubkeys) == 0
+}
+
+// Resolve fetches the watched validators from the head state, resolving public keys to indices.
+// The validators are fetched once per epoch, later calls in the same epoch return the same result.
+func (w *WatchedValidators) Resolve(ctx context.Context, beac beacon.Node) (map[phase0.ValidatorIndex]*v1.Validator, error) {
+	current := beac.Wallclock().Epochs().Current()
+	epoch := current.Number()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.resolvedEpoch != nil && *w.resolvedEpoch == epoch {
+		return w.validators, nil
+	}
+
+	validators, err := beac.FetchValidators(ctx, "head", w.indices, w.pubkeys)
+	if err != nil {
+		return nil, err
+	}
+
+	w.validators = validators
+	w.resolvedEpoch = &epoch
+
+	for index, validator := range validators {
+		name, ok := w.names[fmt.Sprintf("%d", index)]
+		if !ok && validator.Validator != nil {
+			name = w.names[validator.Validator.PublicKey.String()]
+		}
+
+		w.resolved[index] = name
+	}
+
+	return validators, nil
+}
+
+// Indices returns the indices of all resolved validators.
+func (w *WatchedValidators) Indices() []phase0.ValidatorIndex {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	indices := make([]phase0.ValidatorIndex, 0, len(w.resolved))
+	for index := range w.resolved {
+		indices = append(indices, index)
+	}
+
+	return indices
+}
+
+// Contains returns true if the validator index is watched.
+func (w *WatchedValidators) Contains(index phase0.ValidatorIndex) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	_, ok := w.resolved[index]
+
+	return ok
+}
+
+// Labels returns the index and name labels of a validator. The name defaults to the index.
+func (w *WatchedValidators) Labels(index phase0.ValidatorIndex) (indexLabel, name string) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	indexLabel = fmt.Sprintf("%d", index)
+
+	name = w.resolved[index]
+	if name == "" {
+		name = indexLabel
+	}
+
+	return indexLabel, name
+}
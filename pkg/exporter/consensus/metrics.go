@@ -0,0 +1,385 @@
+package consensus
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/jobs"
+	execapi "github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/execution/api"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// Metrics exposes Consensus layer metrics that are not provided by the beacon library.
+type Metrics interface {
+	// StartAsync starts all the metrics jobs. It must be called before the beacon node is started.
+	StartAsync(ctx context.Context)
+	// EventsHandler returns the server-sent events proxy of the beacon event stream, or nil if it is disabled.
+	EventsHandler() http.Handler
+}
+
+// Options holds the configuration for the optional consensus metrics jobs.
+type Options struct {
+	// Validators are the validators whose performance is monitored.
+	Validators []jobs.ValidatorConfig
+	// SlotTracker configures the missed slot and orphaned block tracker.
+	SlotTracker jobs.SlotTrackerConfig
+	// EventTiming enables the event arrival timing histograms. Requires the beacon event stream.
+	EventTiming bool
+	// Blobs configures the blob and data column availability job.
+	Blobs jobs.BlobsConfig
+	// Peers configures the peers by client job.
+	Peers jobs.PeersConfig
+	// CheckpointVerifiers are other trusted beacon nodes, keyed by name, whose finalized checkpoint is
+	// compared with the node's.
+	CheckpointVerifiers map[string]api.ConsensusClient
+	// EventProxy configures the server-sent events proxy of the beacon event stream.
+	EventProxy jobs.EventProxyConfig
+	// EventTopics are the topics the beacon event stream is subscribed to.
+	EventTopics []string
+	// EventSilentEpochs is the number of epochs after which a periodic event topic is reported as silent.
+	EventSilentEpochs uint64
+	// StateSummary configures the chain-wide beacon state summary.
+	StateSummary jobs.StateSummaryConfig
+	// LightClient configures the light client serving job.
+	LightClient jobs.LightClientConfig
+	// ExecutionPayload configures the execution payload and optimistic import job.
+	ExecutionPayload jobs.ExecutionPayloadConfig
+	// Participation configures the network-wide participation job.
+	Participation jobs.ParticipationConfig
+	// Operations configures the slashing, voluntary exit and BLS to execution change job.
+	Operations jobs.OperationsConfig
+	// MevBoost configures monitoring of mev-boost and its relays.
+	MevBoost jobs.MevBoostConfig
+	// Execution is an optional client of the paired execution node, used to cross check fork support.
+	Execution execapi.ExecutionClient
+}
+
+type metrics struct {
+	log           logrus.FieldLogger
+	validators    jobs.Validators
+	duties        jobs.Duties
+	slots         jobs.SlotTracker
+	timing        jobs.EventTiming
+	blobs         jobs.Blobs
+	forks         jobs.ForkSchedule
+	peers         jobs.Peers
+	identity      jobs.Identity
+	checkpoint    jobs.CheckpointVerifier
+	mevBoost      jobs.MevBoost
+	state         jobs.StateSummary
+	proxy         jobs.EventProxy
+	events        jobs.EventStream
+	light         jobs.LightClient
+	payload       jobs.ExecutionPayload
+	participation jobs.Participation
+	operations    jobs.Operations
+
+	enabledJobs map[string]bool
+}
+
+// NewMetrics creates a new consensus Metrics instance
+func NewMetrics(beac beacon.Node, consensusAPI api.ConsensusClient, log logrus.FieldLogger, nodeName, namespace string, opts Options) Metrics {
+	constLabels := make(prometheus.Labels)
+	constLabels["ethereum_role"] = "consensus"
+	constLabels["node_name"] = nodeName
+
+	// The watched validators are shared so that they are only fetched once per epoch.
+	watched := jobs.NewWatchedValidators(log, opts.Validators)
+
+	m := &metrics{
+		log:           log,
+		validators:    jobs.NewValidators(beac, consensusAPI, log, namespace, constLabels, watched),
+		duties:        jobs.NewDuties(beac, consensusAPI, log, namespace, constLabels, watched),
+		slots:         jobs.NewSlotTracker(beac, consensusAPI, log, namespace, constLabels, opts.SlotTracker),
+		timing:        jobs.NewEventTiming(beac, log, namespace, constLabels),
+		blobs:         jobs.NewBlobs(beac, consensusAPI, log, namespace, constLabels),
+		forks:         jobs.NewForkSchedule(beac, consensusAPI, opts.Execution, log, namespace, constLabels),
+		peers:         jobs.NewPeers(beac, consensusAPI, log, namespace, constLabels),
+		identity:      jobs.NewIdentity(beac, consensusAPI, log, namespace, constLabels),
+		checkpoint:    jobs.NewCheckpointVerifier(beac, consensusAPI, log, namespace, constLabels, opts.CheckpointVerifiers),
+		mevBoost:      jobs.NewMevBoost(beac, log, namespace, constLabels, opts.MevBoost, watched),
+		state:         jobs.NewStateSummary(beac, consensusAPI, log, namespace, constLabels, opts.StateSummary),
+		proxy:         jobs.NewEventProxy(beac, log, namespace, constLabels, opts.EventProxy, opts.EventTopics),
+		events:        jobs.NewEventStream(beac, log, namespace, constLabels, opts.EventTopics, opts.EventSilentEpochs),
+		light:         jobs.NewLightClient(beac, consensusAPI, log, namespace, constLabels),
+		payload:       jobs.NewExecutionPayload(beac, consensusAPI, log, namespace, constLabels, watched),
+		participation: jobs.NewParticipation(beac, log, namespace, constLabels),
+		operations:    jobs.NewOperations(beac, consensusAPI, log, namespace, constLabels, watched),
+
+		enabledJobs: make(map[string]bool),
+	}
+
+	if m.validators.Enabled() {
+		m.log.Info("Enabling validator metrics")
+		m.enabledJobs[m.validators.Name()] = true
+
+		prometheus.MustRegister(m.validators.Status)
+		prometheus.MustRegister(m.validators.Balance)
+		prometheus.MustRegister(m.validators.EffectiveBalance)
+		prometheus.MustRegister(m.validators.AttestationReward)
+		prometheus.MustRegister(m.validators.AttestationCorrect)
+		prometheus.MustRegister(m.validators.AttestationsMissed)
+		prometheus.MustRegister(m.validators.Proposals)
+		prometheus.MustRegister(m.validators.SyncCommitteeMember)
+		prometheus.MustRegister(m.validators.SyncCommitteeParticipation)
+		prometheus.MustRegister(m.validators.ProcessedEpoch)
+	}
+
+	if m.duties.Enabled() {
+		m.log.Info("Enabling validator duties metrics")
+		m.enabledJobs[m.duties.Name()] = true
+
+		prometheus.MustRegister(m.duties.NextProposalSlot)
+		prometheus.MustRegister(m.duties.NextProposalSeconds)
+		prometheus.MustRegister(m.duties.CurrentSyncCommitteeMember)
+		prometheus.MustRegister(m.duties.NextSyncCommitteeMember)
+	}
+
+	if opts.SlotTracker.Enabled {
+		m.log.Info("Enabling slot tracker metrics")
+		m.enabledJobs[m.slots.Name()] = true
+
+		prometheus.MustRegister(m.slots.Missed)
+		prometheus.MustRegister(m.slots.Proposed)
+		prometheus.MustRegister(m.slots.Orphaned)
+		prometheus.MustRegister(m.slots.ChainReorgs)
+		prometheus.MustRegister(m.slots.ChainReorgDepth)
+		prometheus.MustRegister(m.slots.MissedRatio)
+	}
+
+	if opts.EventTiming {
+		m.log.Info("Enabling event timing metrics")
+		m.enabledJobs[m.timing.Name()] = true
+
+		prometheus.MustRegister(m.timing.Arrival)
+	}
+
+	m.log.Info("Enabling fork schedule metrics")
+	m.enabledJobs[m.forks.Name()] = true
+
+	prometheus.MustRegister(m.forks.CurrentInfo)
+	prometheus.MustRegister(m.forks.NextEpoch)
+	prometheus.MustRegister(m.forks.NextTimestamp)
+	prometheus.MustRegister(m.forks.NextSeconds)
+	prometheus.MustRegister(m.forks.NextExecutionSupported)
+
+	m.log.Info("Enabling node identity metrics")
+	m.enabledJobs[m.identity.Name()] = true
+
+	prometheus.MustRegister(m.identity.Info)
+	prometheus.MustRegister(m.identity.ENRSeqNumber)
+	prometheus.MustRegister(m.identity.MetadataSeqNumber)
+	prometheus.MustRegister(m.identity.Attnets)
+	prometheus.MustRegister(m.identity.Syncnets)
+	prometheus.MustRegister(m.identity.CustodyGroupCount)
+	prometheus.MustRegister(m.identity.Addresses)
+
+	if opts.Blobs.Enabled {
+		m.log.Info("Enabling blob metrics")
+		m.enabledJobs[m.blobs.Name()] = true
+
+		prometheus.MustRegister(m.blobs.HeadBlobCount)
+		prometheus.MustRegister(m.blobs.BlobsTotal)
+		prometheus.MustRegister(m.blobs.MaxBlobsPerBlock)
+		prometheus.MustRegister(m.blobs.Availability)
+		prometheus.MustRegister(m.blobs.Incomplete)
+		prometheus.MustRegister(m.blobs.RetrievalFailures)
+		prometheus.MustRegister(m.blobs.CustodyColumnsCount)
+
+		m.identity.OnIdentity(m.blobs.ObserveIdentity)
+	}
+
+	if opts.Peers.Enabled {
+		m.log.Info("Enabling peers metrics")
+		m.enabledJobs[m.peers.Name()] = true
+
+		prometheus.MustRegister(m.peers.ByClient)
+	}
+
+	if m.checkpoint.Enabled() {
+		m.log.Info("Enabling checkpoint verifier metrics")
+		m.enabledJobs[m.checkpoint.Name()] = true
+
+		prometheus.MustRegister(m.checkpoint.Agreement)
+		prometheus.MustRegister(m.checkpoint.EpochLag)
+		prometheus.MustRegister(m.checkpoint.Divergences)
+		prometheus.MustRegister(m.checkpoint.FinalizedEpoch)
+		prometheus.MustRegister(m.checkpoint.Errors)
+	}
+
+	if opts.MevBoost.Enabled {
+		m.log.Info("Enabling mev-boost metrics")
+		m.enabledJobs[m.mevBoost.Name()] = true
+
+		prometheus.MustRegister(m.mevBoost.Up)
+		prometheus.MustRegister(m.mevBoost.RelayUp)
+		prometheus.MustRegister(m.mevBoost.RelayLatency)
+		prometheus.MustRegister(m.mevBoost.RelayRegistered)
+		prometheus.MustRegister(m.mevBoost.RelayBlocksDelivered)
+		prometheus.MustRegister(m.mevBoost.RelayValueDelivered)
+		prometheus.MustRegister(m.mevBoost.RelayErrors)
+	}
+
+	if opts.StateSummary.Enabled {
+		m.log.Info("Enabling state summary metrics")
+		m.enabledJobs[m.state.Name()] = true
+
+		prometheus.MustRegister(m.state.Validators)
+		prometheus.MustRegister(m.state.ActivationQueue)
+		prometheus.MustRegister(m.state.ExitQueue)
+		prometheus.MustRegister(m.state.TotalActiveBalance)
+		prometheus.MustRegister(m.state.ChurnLimit)
+		prometheus.MustRegister(m.state.ChurnLimitGwei)
+		prometheus.MustRegister(m.state.PendingDeposits)
+		prometheus.MustRegister(m.state.PendingDepositsGwei)
+		prometheus.MustRegister(m.state.PendingConsolidations)
+		prometheus.MustRegister(m.state.PendingPartialWithdrawals)
+		prometheus.MustRegister(m.state.PendingPartialWithdrawalsGwei)
+	}
+
+	if opts.LightClient.Enabled {
+		m.log.Info("Enabling light client metrics")
+		m.enabledJobs[m.light.Name()] = true
+
+		prometheus.MustRegister(m.light.Served)
+		prometheus.MustRegister(m.light.Latency)
+		prometheus.MustRegister(m.light.SlotLag)
+		prometheus.MustRegister(m.light.Failures)
+	}
+
+	if opts.ExecutionPayload.Enabled {
+		m.log.Info("Enabling execution payload metrics")
+		m.enabledJobs[m.payload.Name()] = true
+
+		prometheus.MustRegister(m.payload.HeadBlockNumber)
+		prometheus.MustRegister(m.payload.HeadBlockHash)
+		prometheus.MustRegister(m.payload.ExecutionOptimistic)
+		prometheus.MustRegister(m.payload.OptimisticBlocks)
+		prometheus.MustRegister(m.payload.ExecutionOffline)
+		prometheus.MustRegister(m.payload.AttributesToBlock)
+	}
+
+	if opts.Participation.Enabled {
+		m.log.Info("Enabling participation metrics")
+		m.enabledJobs[m.participation.Name()] = true
+
+		prometheus.MustRegister(m.participation.HeadRate)
+		prometheus.MustRegister(m.participation.Rate)
+	}
+
+	if opts.Operations.Enabled {
+		m.log.Info("Enabling operations metrics")
+		m.enabledJobs[m.operations.Name()] = true
+
+		prometheus.MustRegister(m.operations.Included)
+		prometheus.MustRegister(m.operations.PoolSeen)
+		prometheus.MustRegister(m.operations.PoolSize)
+		prometheus.MustRegister(m.operations.WatchedValidator)
+	}
+
+	if len(opts.EventTopics) > 0 {
+		m.log.Info("Enabling event stream metrics")
+		m.enabledJobs[m.events.Name()] = true
+
+		prometheus.MustRegister(m.events.SinceLastEvent)
+		prometheus.MustRegister(m.events.Silent)
+		prometheus.MustRegister(m.events.ForkInactive)
+	}
+
+	if opts.EventProxy.Enabled {
+		if len(opts.EventTopics) == 0 {
+			m.log.Warn("Event stream proxy is enabled but the beacon event stream is not, not enabling it")
+		} else {
+			m.log.Info("Enabling event stream proxy")
+			m.enabledJobs[m.proxy.Name()] = true
+
+			prometheus.MustRegister(m.proxy.Subscribers)
+			prometheus.MustRegister(m.proxy.Dropped)
+		}
+	}
+
+	return m
+}
+
+func (m *metrics) StartAsync(ctx context.Context) {
+	if m.enabledJobs[m.validators.Name()] {
+		m.validators.Start(ctx)
+	}
+
+	if m.enabledJobs[m.duties.Name()] {
+		m.duties.Start(ctx)
+	}
+
+	if m.enabledJobs[m.slots.Name()] {
+		m.slots.Start(ctx)
+	}
+
+	if m.enabledJobs[m.timing.Name()] {
+		m.timing.Start(ctx)
+	}
+
+	if m.enabledJobs[m.blobs.Name()] {
+		m.blobs.Start(ctx)
+	}
+
+	if m.enabledJobs[m.forks.Name()] {
+		m.forks.Start(ctx)
+	}
+
+	if m.enabledJobs[m.identity.Name()] {
+		m.identity.Start(ctx)
+	}
+
+	if m.enabledJobs[m.peers.Name()] {
+		m.peers.Start(ctx)
+	}
+
+	if m.enabledJobs[m.checkpoint.Name()] {
+		m.checkpoint.Start(ctx)
+	}
+
+	if m.enabledJobs[m.mevBoost.Name()] {
+		m.mevBoost.Start(ctx)
+	}
+
+	if m.enabledJobs[m.state.Name()] {
+		m.state.Start(ctx)
+	}
+
+	if m.enabledJobs[m.light.Name()] {
+		m.light.Start(ctx)
+	}
+
+	if m.enabledJobs[m.payload.Name()] {
+		m.payload.Start(ctx)
+	}
+
+	if m.enabledJobs[m.participation.Name()] {
+		m.participation.Start(ctx)
+	}
+
+	if m.enabledJobs[m.operations.Name()] {
+		m.operations.Start(ctx)
+	}
+
+	if m.enabledJobs[m.events.Name()] {
+		m.events.Start(ctx)
+	}
+
+	if m.enabledJobs[m.proxy.Name()] {
+		m.proxy.Start(ctx)
+	}
+
+	m.log.Info("Started consensus metrics jobs")
+}
+
+func (m *metrics) EventsHandler() http.Handler {
+	if !m.enabledJobs[m.proxy.Name()] {
+		return nil
+	}
+
+	return &m.proxy
+}
@@ -25,6 +25,8 @@ type ExecutionClient interface {
 	TXPoolStatus(ctx context.Context) (*types.TXPoolStatus, error)
 	// NetPeerCount returns the number of peers.
 	NetPeerCount(ctx context.Context) (int, error)
+	// EthConfig returns the current, next and last fork configuration of the node.
+	EthConfig(ctx context.Context) (*types.EthConfig, error)
 }
 
 type executionClient struct {
@@ -50,6 +52,12 @@ type apiResponse struct {
 	JSONRpc string          `json:"jsonrpc"`
 	ID      int64           `json:"id"`
 	Result  json.RawMessage `json:"result"`
+	Error   *apiError       `json:"error,omitempty"`
+}
+
+type apiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
 }
 
 //nolint:unparam // ctx will probably be used in the future
@@ -87,6 +95,10 @@ func (e *executionClient) post(ctx context.Context, method string, params []stri
 		return nil, err
 	}
 
+	if resp.Error != nil {
+		return nil, fmt.Errorf("rpc error %d: %s", resp.Error.Code, resp.Error.Message)
+	}
+
 	return resp.Result, nil
 }
 
@@ -145,3 +157,17 @@ func (e *executionClient) TXPoolStatus(ctx context.Context) (*types.TXPoolStatus
 
 	return txPoolStatus, nil
 }
+
+func (e *executionClient) EthConfig(ctx context.Context) (*types.EthConfig, error) {
+	rsp, err := e.post(ctx, "eth_config", []string{}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &types.EthConfig{}
+	if err := json.Unmarshal(rsp, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
@@ -0,0 +1,15 @@
+package types
+
+// EthConfig is the fork configuration returned by eth_config (EIP-7910).
+type EthConfig struct {
+	Current *ForkConfig `json:"current"`
+	Next    *ForkConfig `json:"next"`
+	Last    *ForkConfig `json:"last"`
+}
+
+// ForkConfig is the configuration of a single fork.
+type ForkConfig struct {
+	ActivationTime uint64 `json:"activationTime"`
+	ChainID        string `json:"chainId"`
+	ForkID         string `json:"forkId"`
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/execution/api"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/execution/jobs"
 	"github.com/onrik/ethrpc"
 	"github.com/sirupsen/logrus"
 )
@@ -24,6 +25,18 @@ type Node interface {
 	StartMetrics(ctx context.Context)
 }
 
+// Options holds the configuration for the optional execution metrics jobs.
+type Options struct {
+	// ContractCalls are the eth_call watches to export.
+	ContractCalls []jobs.ContractCall
+	// DepositContract configures the deposit contract monitoring job.
+	DepositContract jobs.DepositContractConfig
+	// ContractEvents are the contract events to count as new blocks arrive.
+	ContractEvents []jobs.ContractEvent
+	// Block configures the optional parts of the block job.
+	Block jobs.BlockMetricsOptions
+}
+
 type node struct {
 	name         string
 	url          string
@@ -35,11 +48,11 @@ type node struct {
 }
 
 // NewExecutionNode returns a new execution node.
-func NewExecutionNode(ctx context.Context, log logrus.FieldLogger, namespace, nodeName, url string, enabledModules []string) (Node, error) {
+func NewExecutionNode(ctx context.Context, log logrus.FieldLogger, namespace, nodeName, url string, enabledModules []string, opts Options) (Node, error) {
 	internalAPI := api.NewExecutionClient(ctx, log, url)
 	client, _ := ethclient.Dial(url)
 	ethrpcClient := ethrpc.New(url)
-	metrics := NewMetrics(client, internalAPI, ethrpcClient, log, nodeName, namespace, enabledModules)
+	metrics := NewMetrics(client, internalAPI, ethrpcClient, log, nodeName, namespace, enabledModules, opts)
 
 	node := &node{
 		name:         nodeName,
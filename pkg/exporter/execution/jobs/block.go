@@ -7,6 +7,7 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/execution/api"
 	"github.com/onrik/ethrpc"
@@ -35,9 +36,32 @@ type BlockMetrics struct {
 	SafeBlockSize        prometheus.Counter
 	SafeTransactionCount prometheus.Counter
 
+	BlocksByFeeRecipient prometheus.CounterVec
+	BlocksByExtraData    prometheus.CounterVec
+
+	HeadTransactionsByType prometheus.CounterVec
+	HeadBlobCount          prometheus.Gauge
+	HeadGasUsedByKind      prometheus.CounterVec
+	HeadPriorityFee        prometheus.Histogram
+
+	options  BlockMetricsOptions
+	builders *builderAttributor
+
 	safeDistanceBlocks     uint64
 	currentHeadBlockNumber uint64
 	currentSafeBlockNumber uint64
+
+	headListeners []func(ctx context.Context, number uint64)
+}
+
+// BlockMetricsOptions configures the optional parts of the block job.
+type BlockMetricsOptions struct {
+	// BuilderAttribution enables counting head blocks by fee recipient and extraData builder tag.
+	BuilderAttribution bool
+	// Builders are known builders used for attribution, in addition to the defaults.
+	Builders []KnownBuilder
+	// Composition enables the transaction composition metrics (requires full block and receipt fetches).
+	Composition bool
 }
 
 const (
@@ -55,7 +79,7 @@ func (b *BlockMetrics) RequiredModules() []string {
 }
 
 // NewBlockMetrics returns a new Block metrics instance.
-func NewBlockMetrics(client *ethclient.Client, internalAPI api.ExecutionClient, ethRPCClient *ethrpc.EthRPC, log logrus.FieldLogger, namespace string, constLabels map[string]string) BlockMetrics {
+func NewBlockMetrics(client *ethclient.Client, internalAPI api.ExecutionClient, ethRPCClient *ethrpc.EthRPC, log logrus.FieldLogger, namespace string, constLabels map[string]string, options BlockMetricsOptions) BlockMetrics {
 	constLabels["module"] = NameBlock
 
 	namespace = namespace + "_" + NameBlock
@@ -160,6 +184,73 @@ func NewBlockMetrics(client *ethclient.Client, internalAPI api.ExecutionClient,
 			},
 		),
 
+		BlocksByFeeRecipient: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "head_blocks_by_fee_recipient_total",
+				Help:        "The number of observed head blocks by fee recipient (unknown fee recipients are reported as other).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"fee_recipient",
+				"builder",
+			},
+		),
+		BlocksByExtraData: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "head_blocks_by_extra_data_total",
+				Help:        "The number of observed head blocks by the builder decoded from extraData.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"builder",
+			},
+		),
+
+		HeadTransactionsByType: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "head_transactions_by_type_total",
+				Help:        "The number of transactions in observed head blocks by transaction type.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"type",
+			},
+		),
+		HeadBlobCount: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "head_blob_count",
+				Help:        "The number of blobs in the most recent block.",
+				ConstLabels: constLabels,
+			},
+		),
+		HeadGasUsedByKind: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "head_gas_used_by_kind_total",
+				Help:        "The gas used in observed head blocks by contract creations and calls.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"kind",
+			},
+		),
+		HeadPriorityFee: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace:   namespace,
+				Name:        "head_priority_fee_gwei",
+				Help:        "The effective priority fee (in gwei) of transactions in observed head blocks.",
+				ConstLabels: constLabels,
+				Buckets:     []float64{0, 0.001, 0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10, 50, 100},
+			},
+		),
+
+		options:  options,
+		builders: newBuilderAttributor(options.Builders),
+
 		safeDistanceBlocks: SafeDistanceBlocks,
 
 		currentHeadBlockNumber: 0,
@@ -167,6 +258,11 @@ func NewBlockMetrics(client *ethclient.Client, internalAPI api.ExecutionClient,
 	}
 }
 
+// OnNewHead registers a handler that is called whenever a new head block number is observed.
+func (b *BlockMetrics) OnNewHead(handler func(ctx context.Context, number uint64)) {
+	b.headListeners = append(b.headListeners, handler)
+}
+
 func (b *BlockMetrics) Start(ctx context.Context) {
 	b.tick(ctx)
 
@@ -200,6 +296,26 @@ func (b *BlockMetrics) getHeadBlockStats(ctx context.Context) error {
 	b.currentHeadBlockNumber = mostRecentBlockNumber
 	b.MostRecentBlockNumber.WithLabelValues("head").Set(float64(mostRecentBlockNumber))
 
+	for _, handler := range b.headListeners {
+		handler(ctx, mostRecentBlockNumber)
+	}
+
+	// The composition needs the full transactions, so the head block is then only fetched with them.
+	if b.options.Composition {
+		block, err := b.client.BlockByNumber(ctx, new(big.Int).SetUint64(mostRecentBlockNumber))
+		if err != nil {
+			return err
+		}
+
+		b.observeHeadBlock(block.GasUsed(), block.GasLimit(), block.Size(), len(block.Transactions()), block.Coinbase().Hex(), hexutil.Encode(block.Extra()))
+
+		if err := b.observeBlockComposition(ctx, block); err != nil {
+			return fmt.Errorf("failed to get block composition: %w", err)
+		}
+
+		return nil
+	}
+
 	block, err := b.ethRPCClient.EthGetBlockByNumber(int(mostRecentBlockNumber), false)
 	if err != nil {
 		return err
@@ -209,15 +325,30 @@ func (b *BlockMetrics) getHeadBlockStats(ctx context.Context) error {
 		return errors.New("block is nil")
 	}
 
-	b.HeadGasUsed.Set(float64(block.GasUsed))
-	b.HeadGasLimit.Set(float64(block.GasLimit))
-	b.HeadBlockSize.Set(float64(block.Size))
-	b.HeadTransactionCount.Set(float64(len(block.Transactions)))
-	// b.HeadBaseFeePerGas.Set(float64(block.BaseFee().Int64())) TODO(sam.calder-mason): Fix me
+	b.observeHeadBlock(uint64(block.GasUsed), uint64(block.GasLimit), uint64(block.Size), len(block.Transactions), block.Miner, block.ExtraData)
 
 	return nil
 }
 
+func (b *BlockMetrics) observeHeadBlock(gasUsed, gasLimit, size uint64, transactions int, miner, extraData string) {
+	b.HeadGasUsed.Set(float64(gasUsed))
+	b.HeadGasLimit.Set(float64(gasLimit))
+	b.HeadBlockSize.Set(float64(size))
+	b.HeadTransactionCount.Set(float64(transactions))
+	// b.HeadBaseFeePerGas.Set(float64(block.BaseFee().Int64())) TODO(sam.calder-mason): Fix me
+
+	if b.options.BuilderAttribution {
+		b.observeBuilder(miner, extraData)
+	}
+}
+
+func (b *BlockMetrics) observeBuilder(feeRecipient, extraData string) {
+	address, builder := b.builders.FeeRecipient(feeRecipient)
+
+	b.BlocksByFeeRecipient.WithLabelValues(address, builder).Inc()
+	b.BlocksByExtraData.WithLabelValues(b.builders.ExtraData(extraData)).Inc()
+}
+
 func (b *BlockMetrics) getSafeBlockStats(ctx context.Context) error {
 	mostRecentBlockNumber, err := b.client.BlockNumber(ctx)
 	if err != nil {
@@ -0,0 +1,73 @@
+package jobs
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// BlockCompositionConfig configures the per-block transaction composition metrics.
+// Enabling this fetches every head block with full transactions and its receipts.
+type BlockCompositionConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// TransactionTypeNames maps EIP-2718 transaction types to human-readable names.
+var TransactionTypeNames = map[uint8]string{
+	0: "legacy",
+	1: "access_list",
+	2: "dynamic_fee",
+	3: "blob",
+	4: "set_code",
+}
+
+func (b *BlockMetrics) observeBlockComposition(ctx context.Context, block *types.Block) error {
+	receipts, err := b.client.BlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(block.Hash(), false))
+	if err != nil {
+		return err
+	}
+
+	gasUsed := make(map[common.Hash]uint64, len(receipts))
+	for _, receipt := range receipts {
+		gasUsed[receipt.TxHash] = receipt.GasUsed
+	}
+
+	blobs := 0
+
+	for _, tx := range block.Transactions() {
+		typeName, ok := TransactionTypeNames[tx.Type()]
+		if !ok {
+			typeName = "unknown"
+		}
+
+		b.HeadTransactionsByType.WithLabelValues(typeName).Inc()
+
+		blobs += len(tx.BlobHashes())
+
+		kind := "call"
+		if tx.To() == nil {
+			kind = "create"
+		}
+
+		b.HeadGasUsedByKind.WithLabelValues(kind).Add(float64(gasUsed[tx.Hash()]))
+
+		if block.BaseFee() != nil {
+			tip, err := tx.EffectiveGasTip(block.BaseFee())
+			if err != nil {
+				continue
+			}
+
+			gwei, _ := new(big.Float).Quo(new(big.Float).SetInt(tip), big.NewFloat(params.GWei)).Float64()
+
+			b.HeadPriorityFee.Observe(gwei)
+		}
+	}
+
+	b.HeadBlobCount.Set(float64(blobs))
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package jobs
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"gopkg.in/yaml.v2"
+)
+
+// BuilderAttributionConfig configures the attribution of head blocks to builders.
+type BuilderAttributionConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// MappingFile is an optional YAML file of known builders that extends the defaults.
+	MappingFile string `yaml:"mappingFile"`
+}
+
+// KnownBuilder maps fee recipients and extraData tags to a builder name.
+type KnownBuilder struct {
+	Name          string   `yaml:"name"`
+	FeeRecipients []string `yaml:"feeRecipients"`
+	ExtraData     []string `yaml:"extraData"`
+}
+
+// BuilderMapping is the structure of the builder mapping file.
+type BuilderMapping struct {
+	Builders []KnownBuilder `yaml:"builders"`
+}
+
+const (
+	builderUnknown = "unknown"
+	builderOther   = "other"
+	builderEmpty   = "empty"
+)
+
+// DefaultKnownBuilders are the extraData tags of well-known builders.
+var DefaultKnownBuilders = []KnownBuilder{
+	{Name: "beaverbuild", ExtraData: []string{"beaverbuild"}},
+	{Name: "titan", ExtraData: []string{"titan"}},
+	{Name: "rsync", ExtraData: []string{"rsync"}},
+	{Name: "flashbots", ExtraData: []string{"illuminate dmocratize dstribute", "flashbots"}},
+	{Name: "bloxroute", ExtraData: []string{"bloxroute"}},
+	{Name: "buildernet", ExtraData: []string{"buildernet"}},
+	{Name: "quasar", ExtraData: []string{"quasar"}},
+}
+
+// LoadBuilderMapping reads the known builders from a mapping file.
+func LoadBuilderMapping(path string) ([]KnownBuilder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := BuilderMapping{}
+	if err := yaml.Unmarshal(data, &mapping); err != nil {
+		return nil, err
+	}
+
+	for _, builder := range mapping.Builders {
+		if builder.Name == "" {
+			return nil, fmt.Errorf("builder mapping %s contains a builder without a name", path)
+		}
+
+		for _, address := range builder.FeeRecipients {
+			if !common.IsHexAddress(address) {
+				return nil, fmt.Errorf("builder %s has an invalid fee recipient: %q", builder.Name, address)
+			}
+		}
+	}
+
+	return mapping.Builders, nil
+}
+
+// builderAttributor resolves builder names from fee recipients and extraData.
+type builderAttributor struct {
+	byFeeRecipient map[string]string
+	byTag          []builderTag
+}
+
+type builderTag struct {
+	tag     string
+	builder string
+}
+
+func newBuilderAttributor(builders []KnownBuilder) *builderAttributor {
+	b := &builderAttributor{
+		byFeeRecipient: make(map[string]string),
+	}
+
+	// Configured builders take precedence over the defaults.
+	for _, builder := range append(append([]KnownBuilder{}, builders...), DefaultKnownBuilders...) {
+		for _, address := range builder.FeeRecipients {
+			key := strings.ToLower(common.HexToAddress(address).Hex())
+			if _, exists := b.byFeeRecipient[key]; !exists {
+				b.byFeeRecipient[key] = builder.Name
+			}
+		}
+
+		for _, tag := range builder.ExtraData {
+			b.byTag = append(b.byTag, builderTag{tag: strings.ToLower(tag), builder: builder.Name})
+		}
+	}
+
+	return b
+}
+
+// FeeRecipient returns the fee recipient label and builder name for a fee recipient.
+// Unknown fee recipients are collapsed in to a single label to bound cardinality.
+func (b *builderAttributor) FeeRecipient(feeRecipient string) (address, builder string) {
+	key := strings.ToLower(feeRecipient)
+
+	name, ok := b.byFeeRecipient[key]
+	if !ok {
+		return builderOther, builderUnknown
+	}
+
+	return key, name
+}
+
+// ExtraData returns the builder name for the given hex encoded extraData.
+func (b *builderAttributor) ExtraData(extraData string) string {
+	tag := DecodeExtraData(extraData)
+	if tag == "" {
+		return builderEmpty
+	}
+
+	lower := strings.ToLower(tag)
+
+	for _, t := range b.byTag {
+		if strings.Contains(lower, t.tag) {
+			return t.builder
+		}
+	}
+
+	return builderOther
+}
+
+// DecodeExtraData decodes hex encoded block extraData in to its printable characters.
+func DecodeExtraData(extraData string) string {
+	raw, err := hexutil.Decode(extraData)
+	if err != nil {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	for _, c := range raw {
+		if c >= 0x20 && c < 0x7f {
+			sb.WriteByte(c)
+		}
+	}
+
+	return strings.TrimSpace(sb.String())
+}
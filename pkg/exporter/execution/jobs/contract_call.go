@@ -0,0 +1,456 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/execution/api"
+	"github.com/onrik/ethrpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// ContractCall configures a single eth_call that is watched by the ContractCalls job.
+type ContractCall struct {
+	// Name is used as the `name` label on the exported metrics.
+	Name string `yaml:"name"`
+	// Address is the contract address to call.
+	Address string `yaml:"address"`
+	// Method is either a function signature (e.g. `balanceOf(address)`) or a 4-byte selector (e.g. `0x70a08231`).
+	Method string `yaml:"method"`
+	// Args are the call arguments. When Method is a raw selector each argument must be hex and is padded to 32 bytes.
+	Args []string `yaml:"args"`
+	// Decoder is how the return data is decoded. One of `uint256` (default), `bool`, `address` or
+	// `le_bytes` (a dynamic bytes value holding a little-endian integer, as returned by the deposit contract).
+	Decoder string `yaml:"decoder"`
+	// Decimals scales uint256 results down by 10^decimals (e.g. 18 for most ERC-20 tokens).
+	Decimals int `yaml:"decimals"`
+}
+
+const (
+	DecoderUint256 = "uint256"
+	DecoderBool    = "bool"
+	DecoderAddress = "address"
+	DecoderLEBytes = "le_bytes"
+)
+
+// ContractCalls exposes the results of configured eth_call watches.
+type ContractCalls struct {
+	client       *ethclient.Client
+	api          api.ExecutionClient
+	ethRPCClient *ethrpc.EthRPC
+	log          logrus.FieldLogger
+
+	calls []preparedContractCall
+
+	Value   prometheus.GaugeVec
+	Address prometheus.GaugeVec
+	Errors  prometheus.CounterVec
+}
+
+type preparedContractCall struct {
+	name     string
+	to       common.Address
+	data     []byte
+	decoder  string
+	decimals int
+}
+
+const (
+	NameContractCall = "contract_call"
+)
+
+func (c *ContractCalls) Name() string {
+	return NameContractCall
+}
+
+func (c *ContractCalls) RequiredModules() []string {
+	return []string{"eth"}
+}
+
+// Enabled returns true if there is at least one valid call to watch.
+func (c *ContractCalls) Enabled() bool {
+	return len(c.calls) > 0
+}
+
+// ValidateContractCalls returns an error for the first contract call that is not valid.
+func ValidateContractCalls(calls []ContractCall) error {
+	for i, call := range calls {
+		if _, err := prepareContractCall(call); err != nil {
+			return fmt.Errorf("contract call %d (%s): %w", i, call.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// NewContractCalls returns a new ContractCalls instance.
+func NewContractCalls(client *ethclient.Client, internalAPI api.ExecutionClient, ethRPCClient *ethrpc.EthRPC, log logrus.FieldLogger, namespace string, constLabels map[string]string, calls []ContractCall) ContractCalls {
+	constLabels["module"] = NameContractCall
+
+	namespace += "_contract"
+
+	log = log.WithField("module", NameContractCall)
+
+	prepared := make([]preparedContractCall, 0, len(calls))
+
+	for _, call := range calls {
+		p, err := prepareContractCall(call)
+		if err != nil {
+			log.WithError(err).WithField("name", call.Name).Error("Invalid contract call config, skipping")
+
+			continue
+		}
+
+		prepared = append(prepared, p)
+	}
+
+	return ContractCalls{
+		client:       client,
+		api:          internalAPI,
+		ethRPCClient: ethRPCClient,
+		log:          log,
+		calls:        prepared,
+		Value: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "call_value",
+				Help:        "The decoded result of a watched contract call.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"name",
+			},
+		),
+		Address: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "call_address",
+				Help:        "The address returned by a watched contract call using the address decoder.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"name",
+				"address",
+			},
+		),
+		Errors: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "call_errors_total",
+				Help:        "The number of failed watched contract calls.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"name",
+			},
+		),
+	}
+}
+
+func (c *ContractCalls) Start(ctx context.Context) {
+	c.tick(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second * 15):
+			c.tick(ctx)
+		}
+	}
+}
+
+func (c *ContractCalls) tick(ctx context.Context) {
+	for _, call := range c.calls {
+		if err := c.observe(ctx, call); err != nil {
+			c.Errors.WithLabelValues(call.name).Inc()
+
+			c.log.WithError(err).WithField("name", call.name).Error("Failed to call contract")
+		}
+	}
+}
+
+func (c *ContractCalls) observe(ctx context.Context, call preparedContractCall) error {
+	to := call.to
+
+	result, err := c.client.CallContract(ctx, ethereum.CallMsg{
+		To:   &to,
+		Data: call.data,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	if len(result) < 32 {
+		return fmt.Errorf("unexpected return data length: %d", len(result))
+	}
+
+	word := result[:32]
+
+	switch call.decoder {
+	case DecoderBool:
+		value := 0.0
+		if word[31] != 0 {
+			value = 1
+		}
+
+		c.Value.WithLabelValues(call.name).Set(value)
+	case DecoderAddress:
+		address := common.BytesToAddress(word[12:])
+
+		c.Address.DeletePartialMatch(prometheus.Labels{"name": call.name})
+		c.Address.WithLabelValues(call.name, strings.ToLower(address.Hex())).Set(1)
+	case DecoderLEBytes:
+		value, err := decodeLEBytes(result)
+		if err != nil {
+			return err
+		}
+
+		c.Value.WithLabelValues(call.name).Set(scaleUint256(value, call.decimals))
+	default:
+		c.Value.WithLabelValues(call.name).Set(scaleUint256(new(big.Int).SetBytes(word), call.decimals))
+	}
+
+	return nil
+}
+
+func scaleUint256(value *big.Int, decimals int) float64 {
+	f := new(big.Float).SetInt(value)
+
+	if decimals > 0 {
+		f.Quo(f, new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
+	}
+
+	result, _ := f.Float64()
+
+	return result
+}
+
+// decodeLEBytes decodes an ABI encoded dynamic bytes value holding a little-endian integer.
+func decodeLEBytes(result []byte) (*big.Int, error) {
+	typ, err := abi.NewType("bytes", "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := abi.Arguments{{Type: typ}}.Unpack(result)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, ok := values[0].([]byte)
+	if !ok {
+		return nil, errors.New("unexpected bytes value")
+	}
+
+	be := make([]byte, len(raw))
+	for i, b := range raw {
+		be[len(raw)-1-i] = b
+	}
+
+	return new(big.Int).SetBytes(be), nil
+}
+
+func prepareContractCall(call ContractCall) (preparedContractCall, error) {
+	if call.Name == "" {
+		return preparedContractCall{}, errors.New("name is required")
+	}
+
+	if !common.IsHexAddress(call.Address) {
+		return preparedContractCall{}, fmt.Errorf("invalid address: %q", call.Address)
+	}
+
+	decoder := call.Decoder
+	if decoder == "" {
+		decoder = DecoderUint256
+	}
+
+	switch decoder {
+	case DecoderUint256, DecoderBool, DecoderAddress, DecoderLEBytes:
+	default:
+		return preparedContractCall{}, fmt.Errorf("unknown decoder: %q", decoder)
+	}
+
+	data, err := EncodeContractCall(call.Method, call.Args)
+	if err != nil {
+		return preparedContractCall{}, err
+	}
+
+	return preparedContractCall{
+		name:     call.Name,
+		to:       common.HexToAddress(call.Address),
+		data:     data,
+		decoder:  decoder,
+		decimals: call.Decimals,
+	}, nil
+}
+
+// EncodeContractCall builds the calldata for a method and its arguments. The method
+// can either be a function signature such as `balanceOf(address)` or a raw 4-byte selector.
+func EncodeContractCall(method string, args []string) ([]byte, error) {
+	method = strings.TrimSpace(method)
+
+	if strings.HasPrefix(method, "0x") {
+		selector, err := hexutil.Decode(method)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", method, err)
+		}
+
+		if len(selector) != 4 {
+			return nil, fmt.Errorf("selector %q must be 4 bytes", method)
+		}
+
+		data := selector
+
+		for _, arg := range args {
+			word, err := hexutil.Decode(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid argument %q: %w", arg, err)
+			}
+
+			if len(word) > 32 {
+				return nil, fmt.Errorf("argument %q is longer than 32 bytes", arg)
+			}
+
+			data = append(data, common.LeftPadBytes(word, 32)...)
+		}
+
+		return data, nil
+	}
+
+	open := strings.Index(method, "(")
+	if open <= 0 || !strings.HasSuffix(method, ")") {
+		return nil, fmt.Errorf("invalid method signature: %q", method)
+	}
+
+	var typeNames []string
+	if inner := method[open+1 : len(method)-1]; inner != "" {
+		typeNames = strings.Split(inner, ",")
+	}
+
+	if len(typeNames) != len(args) {
+		return nil, fmt.Errorf("method %q expects %d arguments, got %d", method, len(typeNames), len(args))
+	}
+
+	arguments := make(abi.Arguments, 0, len(typeNames))
+	values := make([]any, 0, len(args))
+
+	for i, typeName := range typeNames {
+		typeName = strings.TrimSpace(typeName)
+
+		typ, err := abi.NewType(typeName, "", nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument type %q: %w", typeName, err)
+		}
+
+		value, err := parseContractCallArg(typ, args[i])
+		if err != nil {
+			return nil, err
+		}
+
+		arguments = append(arguments, abi.Argument{Type: typ})
+		values = append(values, value)
+
+		typeNames[i] = typeName
+	}
+
+	packed, err := arguments.Pack(values...)
+	if err != nil {
+		return nil, err
+	}
+
+	signature := method[:open] + "(" + strings.Join(typeNames, ",") + ")"
+
+	return append(crypto.Keccak256([]byte(signature))[:4], packed...), nil
+}
+
+func parseContractCallArg(typ abi.Type, arg string) (any, error) {
+	switch typ.T {
+	case abi.AddressTy:
+		if !common.IsHexAddress(arg) {
+			return nil, fmt.Errorf("invalid address argument: %q", arg)
+		}
+
+		return common.HexToAddress(arg), nil
+	case abi.BoolTy:
+		return strconv.ParseBool(arg)
+	case abi.StringTy:
+		return arg, nil
+	case abi.UintTy, abi.IntTy:
+		value, ok := new(big.Int).SetString(arg, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer argument: %q", arg)
+		}
+
+		if err := checkIntegerRange(typ, value); err != nil {
+			return nil, fmt.Errorf("invalid integer argument %q: %w", arg, err)
+		}
+
+		if typ.Size > 64 {
+			return value, nil
+		}
+
+		// Smaller integers must be packed using their exact Go type (e.g. uint8).
+		v := reflect.New(typ.GetType()).Elem()
+		if typ.T == abi.UintTy {
+			v.SetUint(value.Uint64())
+		} else {
+			v.SetInt(value.Int64())
+		}
+
+		return v.Interface(), nil
+	case abi.FixedBytesTy:
+		raw, err := hexutil.Decode(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bytes argument %q: %w", arg, err)
+		}
+
+		if len(raw) > typ.Size {
+			return nil, fmt.Errorf("argument %q is longer than %d bytes", arg, typ.Size)
+		}
+
+		v := reflect.New(typ.GetType()).Elem()
+		reflect.Copy(v, reflect.ValueOf(raw))
+
+		return v.Interface(), nil
+	case abi.BytesTy:
+		return hexutil.Decode(arg)
+	default:
+		return nil, fmt.Errorf("unsupported argument type: %s", typ.String())
+	}
+}
+
+// checkIntegerRange returns an error if the value does not fit the integer type, as packing
+// would silently truncate it.
+func checkIntegerRange(typ abi.Type, value *big.Int) error {
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(typ.Size))
+
+	if typ.T == abi.UintTy {
+		if value.Sign() < 0 || value.Cmp(limit) >= 0 {
+			return fmt.Errorf("out of range for %s", typ.String())
+		}
+
+		return nil
+	}
+
+	limit.Rsh(limit, 1)
+
+	if value.Cmp(new(big.Int).Neg(limit)) < 0 || value.Cmp(limit) >= 0 {
+		return fmt.Errorf("out of range for %s", typ.String())
+	}
+
+	return nil
+}
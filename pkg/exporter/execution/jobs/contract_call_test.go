@@ -0,0 +1,98 @@
+package jobs
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestEncodeContractCall(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "signature without arguments",
+			method: "get_deposit_count()",
+			want:   "0x621fd130",
+		},
+		{
+			name:   "signature with address argument",
+			method: "balanceOf(address)",
+			args:   []string{"0x00000000219ab540356cBB839Cbe05303d7705Fa"},
+			want:   "0x70a0823100000000000000000000000000000000219ab540356cbb839cbe05303d7705fa",
+		},
+		{
+			name:   "signature with multiple arguments and whitespace",
+			method: "transfer(address, uint256)",
+			args:   []string{"0x00000000219ab540356cBB839Cbe05303d7705Fa", "0x12"},
+			want:   "0xa9059cbb00000000000000000000000000000000219ab540356cbb839cbe05303d7705fa0000000000000000000000000000000000000000000000000000000000000012",
+		},
+		{
+			name:   "raw selector with padded argument",
+			method: "0x70a08231",
+			args:   []string{"0x00000000219ab540356cBB839Cbe05303d7705Fa"},
+			want:   "0x70a0823100000000000000000000000000000000219ab540356cbb839cbe05303d7705fa",
+		},
+		{
+			name:    "argument count mismatch",
+			method:  "balanceOf(address)",
+			wantErr: true,
+		},
+		{
+			name:    "invalid selector length",
+			method:  "0x70a082",
+			wantErr: true,
+		},
+		{
+			name:   "small integer arguments at their bounds",
+			method: "f(uint8,int8)",
+			args:   []string{"255", "-128"},
+			want:   "0x439a0f7e00000000000000000000000000000000000000000000000000000000000000ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff80",
+		},
+		{
+			name:    "unsigned integer argument too large",
+			method:  "f(uint8)",
+			args:    []string{"300"},
+			wantErr: true,
+		},
+		{
+			name:    "negative unsigned integer argument",
+			method:  "f(uint256)",
+			args:    []string{"-1"},
+			wantErr: true,
+		},
+		{
+			name:    "signed integer argument too small",
+			method:  "f(int8)",
+			args:    []string{"-129"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid address argument",
+			method:  "balanceOf(address)",
+			args:    []string{"not-an-address"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeContractCall(tt.method, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EncodeContractCall() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if hexutil.Encode(got) != tt.want {
+				t.Errorf("EncodeContractCall() = %s, want %s", hexutil.Encode(got), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/execution/api"
+	"github.com/onrik/ethrpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// ContractEvent configures a contract event that is counted by the ContractEvents job.
+type ContractEvent struct {
+	// Name is used as the `name` label on the exported metrics.
+	Name string `yaml:"name"`
+	// Address is the contract address emitting the event.
+	Address string `yaml:"address"`
+	// Topic0 is either the event signature (e.g. `Transfer(address,address,uint256)`) or its hash.
+	Topic0 string `yaml:"topic0"`
+	// Topics are optional filters for topic1..topic3. An empty string matches any value.
+	Topics []string `yaml:"topics"`
+}
+
+// ContractEvents counts contract events as new blocks are observed by the block job.
+type ContractEvents struct {
+	client       *ethclient.Client
+	api          api.ExecutionClient
+	ethRPCClient *ethrpc.EthRPC
+	log          logrus.FieldLogger
+
+	events      []preparedContractEvent
+	reorgWindow uint64
+
+	Events        prometheus.CounterVec
+	Reorged       prometheus.CounterVec
+	Errors        prometheus.CounterVec
+	SkippedBlocks prometheus.Counter
+
+	lastProcessed uint64
+	// seen holds the logs counted per event name and block number within the reorg window.
+	seen map[string]map[uint64]map[string]struct{}
+}
+
+type preparedContractEvent struct {
+	name    string
+	address common.Address
+	topics  [][]common.Hash
+}
+
+const (
+	NameContractEvents = "contract_events"
+
+	// maxContractEventsRange limits how many blocks are queried at once after a long gap.
+	maxContractEventsRange = 1000
+)
+
+func (c *ContractEvents) Name() string {
+	return NameContractEvents
+}
+
+func (c *ContractEvents) RequiredModules() []string {
+	return []string{"eth", "net"}
+}
+
+// Enabled returns true if there is at least one valid event to count.
+func (c *ContractEvents) Enabled() bool {
+	return len(c.events) > 0
+}
+
+// NewContractEvents returns a new ContractEvents instance.
+func NewContractEvents(client *ethclient.Client, internalAPI api.ExecutionClient, ethRPCClient *ethrpc.EthRPC, log logrus.FieldLogger, namespace string, constLabels map[string]string, events []ContractEvent) ContractEvents {
+	constLabels["module"] = NameContractEvents
+
+	namespace += "_contract"
+
+	log = log.WithField("module", NameContractEvents)
+
+	prepared := make([]preparedContractEvent, 0, len(events))
+
+	for _, event := range events {
+		p, err := prepareContractEvent(event)
+		if err != nil {
+			log.WithError(err).WithField("name", event.Name).Error("Invalid contract event config, skipping")
+
+			continue
+		}
+
+		prepared = append(prepared, p)
+	}
+
+	return ContractEvents{
+		client:       client,
+		api:          internalAPI,
+		ethRPCClient: ethRPCClient,
+		log:          log,
+		events:       prepared,
+		reorgWindow:  SafeDistanceBlocks,
+		seen:         make(map[string]map[uint64]map[string]struct{}),
+		Events: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "events_total",
+				Help:        "The number of watched contract events observed. This is a gross count: a log that is re-included after a reorg is counted again, subtract events_reorged_total for the net count.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"name",
+			},
+		),
+		Reorged: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "events_reorged_total",
+				Help:        "The number of counted contract events that were later removed by a reorg.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"name",
+			},
+		),
+		Errors: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "events_errors_total",
+				Help:        "The number of failed contract event queries.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"name",
+			},
+		),
+		SkippedBlocks: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "events_skipped_blocks_total",
+				Help:        "The number of blocks that were not queried for contract events because the gap since the last head was too large.",
+				ConstLabels: constLabels,
+			},
+		),
+	}
+}
+
+// OnNewHead is called by the block job whenever a new head block is observed.
+func (c *ContractEvents) OnNewHead(ctx context.Context, head uint64) {
+	from := head
+
+	if c.lastProcessed != 0 && c.lastProcessed < head {
+		from = c.lastProcessed + 1
+	}
+
+	// Always re-query the most recent blocks so that logs in reorged blocks are picked up.
+	if head >= c.reorgWindow && from > head-c.reorgWindow+1 {
+		from = head - c.reorgWindow + 1
+	}
+
+	if head-from >= maxContractEventsRange {
+		skipped := head - maxContractEventsRange + 1 - from
+
+		c.log.WithField("from", from).WithField("to", from+skipped-1).Warn("Gap since the last head is too large, skipping blocks for contract events")
+
+		c.SkippedBlocks.Add(float64(skipped))
+
+		from = head - maxContractEventsRange + 1
+	}
+
+	for _, event := range c.events {
+		if err := c.observe(ctx, event, from, head); err != nil {
+			c.Errors.WithLabelValues(event.name).Inc()
+
+			c.log.WithError(err).WithField("name", event.name).Error("Failed to get contract events")
+		}
+	}
+
+	c.lastProcessed = head
+}
+
+func (c *ContractEvents) observe(ctx context.Context, event preparedContractEvent, from, to uint64) error {
+	logs, err := c.client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []common.Address{event.address},
+		Topics:    event.topics,
+	})
+	if err != nil {
+		return err
+	}
+
+	current := make(map[uint64]map[string]struct{})
+
+	for i := range logs {
+		if logs[i].Removed {
+			continue
+		}
+
+		if current[logs[i].BlockNumber] == nil {
+			current[logs[i].BlockNumber] = make(map[string]struct{})
+		}
+
+		current[logs[i].BlockNumber][fmt.Sprintf("%s:%d", logs[i].BlockHash.Hex(), logs[i].Index)] = struct{}{}
+	}
+
+	seen, ok := c.seen[event.name]
+	if !ok {
+		seen = make(map[uint64]map[string]struct{})
+		c.seen[event.name] = seen
+	}
+
+	for number := from; number <= to; number++ {
+		previous := seen[number]
+
+		for key := range previous {
+			if _, exists := current[number][key]; !exists {
+				c.Reorged.WithLabelValues(event.name).Inc()
+			}
+		}
+
+		for key := range current[number] {
+			if _, exists := previous[key]; !exists {
+				c.Events.WithLabelValues(event.name).Inc()
+			}
+		}
+
+		if len(current[number]) == 0 {
+			delete(seen, number)
+		} else {
+			seen[number] = current[number]
+		}
+	}
+
+	for number := range seen {
+		if number+c.reorgWindow <= to {
+			delete(seen, number)
+		}
+	}
+
+	return nil
+}
+
+func prepareContractEvent(event ContractEvent) (preparedContractEvent, error) {
+	if event.Name == "" {
+		return preparedContractEvent{}, errors.New("name is required")
+	}
+
+	if !common.IsHexAddress(event.Address) {
+		return preparedContractEvent{}, fmt.Errorf("invalid address: %q", event.Address)
+	}
+
+	if event.Topic0 == "" {
+		return preparedContractEvent{}, errors.New("topic0 is required")
+	}
+
+	if len(event.Topics) > 3 {
+		return preparedContractEvent{}, errors.New("at most 3 topic filters are supported")
+	}
+
+	topic0, err := parseTopic(event.Topic0)
+	if err != nil {
+		return preparedContractEvent{}, err
+	}
+
+	topics := [][]common.Hash{{topic0}}
+
+	for _, t := range event.Topics {
+		if t == "" {
+			topics = append(topics, nil)
+
+			continue
+		}
+
+		topic, err := parseTopic(t)
+		if err != nil {
+			return preparedContractEvent{}, err
+		}
+
+		topics = append(topics, []common.Hash{topic})
+	}
+
+	return preparedContractEvent{
+		name:    event.Name,
+		address: common.HexToAddress(event.Address),
+		topics:  topics,
+	}, nil
+}
+
+// parseTopic parses a topic given as an event signature, a 32-byte hash or a
+// shorter hex value (e.g. an address) that is left padded to 32 bytes.
+func parseTopic(topic string) (common.Hash, error) {
+	topic = strings.TrimSpace(topic)
+
+	if strings.Contains(topic, "(") {
+		return crypto.Keccak256Hash([]byte(strings.ReplaceAll(topic, " ", ""))), nil
+	}
+
+	raw, err := hexutil.Decode(topic)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("invalid topic %q: %w", topic, err)
+	}
+
+	if len(raw) > common.HashLength {
+		return common.Hash{}, fmt.Errorf("topic %q is longer than 32 bytes", topic)
+	}
+
+	return common.BytesToHash(raw), nil
+}
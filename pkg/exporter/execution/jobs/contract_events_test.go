@@ -0,0 +1,175 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+const testContractAddress = "0x00000000219ab540356cBB839Cbe05303d7705Fa"
+
+type fakeLog struct {
+	block uint64
+	hash  common.Hash
+	index uint
+}
+
+// fakeLogsServer is a minimal JSON-RPC server that answers eth_getLogs from an in-memory chain.
+type fakeLogsServer struct {
+	mu    sync.Mutex
+	logs  []fakeLog
+	calls int
+}
+
+func (f *fakeLogsServer) setLogs(logs []fakeLog) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.logs = logs
+}
+
+func (f *fakeLogsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params []struct {
+			FromBlock hexutil.Uint64 `json:"fromBlock"`
+			ToBlock   hexutil.Uint64 `json:"toBlock"`
+		} `json:"params"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if req.Method != "eth_getLogs" || len(req.Params) != 1 {
+		http.Error(w, "unsupported method", http.StatusBadRequest)
+
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+
+	result := []map[string]any{}
+
+	for _, l := range f.logs {
+		if l.block < uint64(req.Params[0].FromBlock) || l.block > uint64(req.Params[0].ToBlock) {
+			continue
+		}
+
+		result = append(result, map[string]any{
+			"address":          testContractAddress,
+			"topics":           []string{crypto.Keccak256Hash([]byte("Ping()")).Hex()},
+			"data":             "0x",
+			"blockNumber":      hexutil.Uint64(l.block),
+			"blockHash":        l.hash,
+			"transactionHash":  common.Hash{},
+			"transactionIndex": "0x0",
+			"logIndex":         hexutil.Uint(l.index),
+			"removed":          false,
+		})
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      req.ID,
+		"result":  result,
+	})
+}
+
+func TestContractEvents_OnNewHead(t *testing.T) {
+	fake := &fakeLogsServer{}
+
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := ethclient.Dial(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	job := NewContractEvents(client, nil, nil, logrus.New(), "test", prometheus.Labels{}, []ContractEvent{
+		{
+			Name:    "ping",
+			Address: testContractAddress,
+			Topic0:  "Ping()",
+		},
+	})
+
+	if !job.Enabled() {
+		t.Fatal("expected job to be enabled")
+	}
+
+	ctx := context.Background()
+	hashA := common.HexToHash("0xa")
+	hashB := common.HexToHash("0xb")
+	hashC := common.HexToHash("0xc")
+
+	// Head 100 with a single event.
+	fake.setLogs([]fakeLog{{block: 100, hash: hashA, index: 0}})
+	job.OnNewHead(ctx, 100)
+
+	if got := testutil.ToFloat64(job.Events.WithLabelValues("ping")); got != 1 {
+		t.Fatalf("events after head 100 = %v, want 1", got)
+	}
+
+	// Head 101 re-queries the window, the event in block 100 must not be double counted.
+	fake.setLogs([]fakeLog{{block: 100, hash: hashA, index: 0}, {block: 101, hash: hashB, index: 0}})
+	job.OnNewHead(ctx, 101)
+
+	if got := testutil.ToFloat64(job.Events.WithLabelValues("ping")); got != 2 {
+		t.Fatalf("events after head 101 = %v, want 2", got)
+	}
+
+	// Block 101 is reorged out and replaced by a block with two events, then head moves to 102.
+	fake.setLogs([]fakeLog{
+		{block: 100, hash: hashA, index: 0},
+		{block: 101, hash: hashC, index: 0},
+		{block: 101, hash: hashC, index: 1},
+	})
+	job.OnNewHead(ctx, 102)
+
+	if got := testutil.ToFloat64(job.Events.WithLabelValues("ping")); got != 4 {
+		t.Errorf("events after reorg = %v, want 4", got)
+	}
+
+	if got := testutil.ToFloat64(job.Reorged.WithLabelValues("ping")); got != 1 {
+		t.Errorf("reorged events = %v, want 1", got)
+	}
+
+	if got := testutil.ToFloat64(job.Errors.WithLabelValues("ping")); got != 0 {
+		t.Errorf("errors = %v, want 0", got)
+	}
+
+	if fake.calls != 3 {
+		t.Errorf("eth_getLogs calls = %d, want 3", fake.calls)
+	}
+}
+
+func TestContractEvents_InvalidConfig(t *testing.T) {
+	job := NewContractEvents(nil, nil, nil, logrus.New(), "test", prometheus.Labels{}, []ContractEvent{
+		{Name: "no-address", Topic0: "Ping()"},
+		{Name: "no-topic", Address: testContractAddress},
+		{Name: "bad-topic", Address: testContractAddress, Topic0: "0xzz"},
+	})
+
+	if job.Enabled() {
+		t.Error("expected job with only invalid events to be disabled")
+	}
+}
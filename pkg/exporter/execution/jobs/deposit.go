@@ -0,0 +1,476 @@
+package jobs
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/execution/api"
+	"github.com/onrik/ethrpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// DepositContractConfig configures the deposit contract monitoring job.
+type DepositContractConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Address overrides the deposit contract address that is otherwise derived from the chain id.
+	Address string `yaml:"address"`
+	// CursorFile is where the last processed block is persisted so restarts don't rescan history.
+	CursorFile string `yaml:"cursorFile"`
+	// WindowBlocks is the number of recent blocks that the windowed deposit count covers.
+	WindowBlocks uint64 `yaml:"windowBlocks"`
+	// Confirmations is the number of blocks the scan stays behind the head, so that deposits are
+	// only counted once they are unlikely to be reorged out.
+	Confirmations *uint64 `yaml:"confirmations"`
+}
+
+// DepositContract exposes metrics on deposits made to the beacon chain deposit contract.
+type DepositContract struct {
+	client       *ethclient.Client
+	api          api.ExecutionClient
+	ethRPCClient *ethrpc.EthRPC
+	log          logrus.FieldLogger
+	config       DepositContractConfig
+
+	DepositCount  prometheus.Gauge
+	Deposits      prometheus.Counter
+	DepositedGwei prometheus.Counter
+	WindowCount   prometheus.Gauge
+	Requests      prometheus.Counter
+	CursorBlock   prometheus.Gauge
+
+	address common.Address
+	cursor  depositCursor
+	window  []depositWindowEntry
+}
+
+type depositCursor struct {
+	Block        uint64 `json:"block"`
+	DepositCount uint64 `json:"deposit_count"`
+}
+
+type depositWindowEntry struct {
+	block uint64
+	count int
+}
+
+const (
+	NameDepositContract = "deposit_contract"
+
+	// DefaultDepositWindowBlocks is roughly an hour of mainnet blocks.
+	DefaultDepositWindowBlocks = 300
+
+	// DefaultDepositConfirmations is the default number of blocks the scan stays behind the head.
+	DefaultDepositConfirmations = 8
+
+	depositLogBatchSize = 1000
+)
+
+var (
+	// depositCountSelector is the selector of get_deposit_count().
+	depositCountSelector = common.FromHex("0x621fd130")
+
+	// DepositEventTopic is keccak256("DepositEvent(bytes,bytes,bytes,bytes,bytes)").
+	DepositEventTopic = common.HexToHash("0x649bbc62d0e31342afea4e5cd82d4049e7e1ee912fc0889aa790803be39038c5")
+
+	// KnownDepositContracts maps chain ids to their deposit contract address.
+	KnownDepositContracts = map[uint64]common.Address{
+		1:        common.HexToAddress("0x00000000219ab540356cBB839Cbe05303d7705Fa"),
+		100:      common.HexToAddress("0x0B98057eA310F4d31F2a452B414647007d1645d9"),
+		17000:    common.HexToAddress("0x4242424242424242424242424242424242424242"),
+		560048:   common.HexToAddress("0x00000000219ab540356cBB839Cbe05303d7705Fa"),
+		11155111: common.HexToAddress("0x7f02C3E3c98b133055B8B348B2Ac625669Ed295D"),
+	}
+)
+
+func (d *DepositContract) Name() string {
+	return NameDepositContract
+}
+
+func (d *DepositContract) RequiredModules() []string {
+	return []string{"eth"}
+}
+
+// NewDepositContract returns a new DepositContract instance.
+func NewDepositContract(client *ethclient.Client, internalAPI api.ExecutionClient, ethRPCClient *ethrpc.EthRPC, log logrus.FieldLogger, namespace string, constLabels map[string]string, config DepositContractConfig) DepositContract {
+	constLabels["module"] = NameDepositContract
+
+	namespace += "_" + NameDepositContract
+
+	if config.WindowBlocks == 0 {
+		config.WindowBlocks = DefaultDepositWindowBlocks
+	}
+
+	if config.Confirmations == nil {
+		confirmations := uint64(DefaultDepositConfirmations)
+		config.Confirmations = &confirmations
+	}
+
+	return DepositContract{
+		client:       client,
+		api:          internalAPI,
+		ethRPCClient: ethRPCClient,
+		log:          log.WithField("module", NameDepositContract),
+		config:       config,
+		DepositCount: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "deposit_count",
+				Help:        "The total number of deposits made to the deposit contract.",
+				ConstLabels: constLabels,
+			},
+		),
+		Deposits: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "deposits_total",
+				Help:        "The number of deposits observed by the exporter.",
+				ConstLabels: constLabels,
+			},
+		),
+		DepositedGwei: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "deposited_gwei_total",
+				Help:        "The amount of ETH (in gwei) deposited in the deposits observed by the exporter.",
+				ConstLabels: constLabels,
+			},
+		),
+		WindowCount: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "window_deposits",
+				Help:        fmt.Sprintf("The number of deposits made in the last %d blocks.", config.WindowBlocks),
+				ConstLabels: constLabels,
+			},
+		),
+		Requests: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "deposit_requests_total",
+				Help:        "The number of observed deposits included as EIP-6110 deposit requests (blocks with a requestsHash).",
+				ConstLabels: constLabels,
+			},
+		),
+		CursorBlock: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "cursor_block",
+				Help:        "The last block that has been scanned for deposits. The scan stays the configured number of confirmations behind the head.",
+				ConstLabels: constLabels,
+			},
+		),
+	}
+}
+
+func (d *DepositContract) Start(ctx context.Context) {
+	d.tick(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second * 12):
+			d.tick(ctx)
+		}
+	}
+}
+
+func (d *DepositContract) tick(ctx context.Context) {
+	if err := d.scan(ctx); err != nil {
+		d.log.WithError(err).Error("Failed to scan deposit contract")
+	}
+}
+
+func (d *DepositContract) scan(ctx context.Context) error {
+	if d.address == (common.Address{}) {
+		address, err := d.resolveAddress(ctx)
+		if err != nil {
+			return err
+		}
+
+		d.address = address
+
+		d.log.WithField("address", address.Hex()).Info("Watching deposit contract")
+	}
+
+	latest, err := d.client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	confirmations := *d.config.Confirmations
+	if latest < confirmations {
+		return nil
+	}
+
+	head := latest - confirmations
+
+	if d.cursor.Block == 0 {
+		d.cursor = d.loadCursor(head)
+
+		// Deposits are otherwise only counted once a new deposit event is observed.
+		count, err := d.depositCount(ctx, head)
+		if err != nil {
+			d.log.WithError(err).Warn("Failed to get the deposit count from the deposit contract")
+		} else if count > d.cursor.DepositCount {
+			d.cursor.DepositCount = count
+		}
+
+		d.DepositCount.Set(float64(d.cursor.DepositCount))
+	}
+
+	for d.cursor.Block < head {
+		from := d.cursor.Block + 1
+
+		to := from + depositLogBatchSize - 1
+		if to > head {
+			to = head
+		}
+
+		if err := d.scanRange(ctx, from, to); err != nil {
+			return err
+		}
+
+		d.cursor.Block = to
+		d.CursorBlock.Set(float64(to))
+
+		if err := d.saveCursor(); err != nil {
+			d.log.WithError(err).Warn("Failed to persist deposit cursor")
+		}
+	}
+
+	d.pruneWindow(head)
+
+	return nil
+}
+
+func (d *DepositContract) scanRange(ctx context.Context, from, to uint64) error {
+	logs, err := d.client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []common.Address{d.address},
+		Topics:    [][]common.Hash{{DepositEventTopic}},
+	})
+	if err != nil {
+		return err
+	}
+
+	perBlock := make(map[uint64]int)
+
+	for i := range logs {
+		if logs[i].Removed {
+			continue
+		}
+
+		deposit, err := DecodeDepositEvent(logs[i].Data)
+		if err != nil {
+			d.log.WithError(err).WithField("tx", logs[i].TxHash.Hex()).Warn("Failed to decode deposit event")
+
+			continue
+		}
+
+		d.Deposits.Inc()
+		d.DepositedGwei.Add(float64(deposit.AmountGwei))
+
+		if deposit.Index+1 > d.cursor.DepositCount {
+			d.cursor.DepositCount = deposit.Index + 1
+		}
+
+		perBlock[logs[i].BlockNumber]++
+	}
+
+	for block, count := range perBlock {
+		d.window = append(d.window, depositWindowEntry{block: block, count: count})
+
+		header, err := d.client.HeaderByNumber(ctx, new(big.Int).SetUint64(block))
+		if err != nil {
+			d.log.WithError(err).WithField("block", block).Warn("Failed to fetch header for deposit block")
+
+			continue
+		}
+
+		if header.RequestsHash != nil {
+			d.Requests.Add(float64(count))
+		}
+	}
+
+	d.DepositCount.Set(float64(d.cursor.DepositCount))
+
+	return nil
+}
+
+// depositCount returns the number of deposits made up to and including the block.
+func (d *DepositContract) depositCount(ctx context.Context, block uint64) (uint64, error) {
+	result, err := d.client.CallContract(ctx, ethereum.CallMsg{
+		To:   &d.address,
+		Data: depositCountSelector,
+	}, new(big.Int).SetUint64(block))
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := decodeLEBytes(result)
+	if err != nil {
+		return 0, err
+	}
+
+	if !count.IsUint64() {
+		return 0, errors.New("deposit count does not fit in 64 bits")
+	}
+
+	return count.Uint64(), nil
+}
+
+func (d *DepositContract) pruneWindow(head uint64) {
+	total := 0
+	kept := d.window[:0]
+
+	for _, entry := range d.window {
+		if entry.block+d.config.WindowBlocks <= head {
+			continue
+		}
+
+		kept = append(kept, entry)
+		total += entry.count
+	}
+
+	d.window = kept
+
+	d.WindowCount.Set(float64(total))
+}
+
+func (d *DepositContract) resolveAddress(ctx context.Context) (common.Address, error) {
+	if d.config.Address != "" {
+		if !common.IsHexAddress(d.config.Address) {
+			return common.Address{}, fmt.Errorf("invalid deposit contract address: %q", d.config.Address)
+		}
+
+		return common.HexToAddress(d.config.Address), nil
+	}
+
+	chainID, err := d.client.ChainID(ctx)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	address, ok := KnownDepositContracts[chainID.Uint64()]
+	if !ok {
+		return common.Address{}, fmt.Errorf("no known deposit contract for chain id %d, please configure an address", chainID.Uint64())
+	}
+
+	return address, nil
+}
+
+// loadCursor returns the persisted cursor, or a cursor starting at the beginning of the window if none exists.
+func (d *DepositContract) loadCursor(head uint64) depositCursor {
+	start := depositCursor{}
+	if head > d.config.WindowBlocks {
+		start.Block = head - d.config.WindowBlocks
+	}
+
+	if d.config.CursorFile == "" {
+		return start
+	}
+
+	data, err := os.ReadFile(d.config.CursorFile)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			d.log.WithError(err).Warn("Failed to read deposit cursor")
+		}
+
+		return start
+	}
+
+	cursor := depositCursor{}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		d.log.WithError(err).Warn("Failed to parse deposit cursor")
+
+		return start
+	}
+
+	if cursor.Block > head {
+		d.log.WithField("cursor", cursor.Block).WithField("head", head).Warn("Deposit cursor is ahead of the chain head, ignoring")
+
+		return start
+	}
+
+	return cursor
+}
+
+func (d *DepositContract) saveCursor() error {
+	if d.config.CursorFile == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(d.cursor)
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(filepath.Dir(d.config.CursorFile), "."+filepath.Base(d.config.CursorFile)+".tmp")
+
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, d.config.CursorFile)
+}
+
+// DepositEvent is a decoded deposit contract DepositEvent log.
+type DepositEvent struct {
+	Pubkey                []byte
+	WithdrawalCredentials []byte
+	AmountGwei            uint64
+	Signature             []byte
+	Index                 uint64
+}
+
+// DecodeDepositEvent decodes the data of a DepositEvent log.
+func DecodeDepositEvent(data []byte) (*DepositEvent, error) {
+	typ, err := abi.NewType("bytes", "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	args := abi.Arguments{{Type: typ}, {Type: typ}, {Type: typ}, {Type: typ}, {Type: typ}}
+
+	values, err := args.Unpack(data)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([][]byte, len(values))
+
+	for i, v := range values {
+		b, ok := v.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type for field %d", i)
+		}
+
+		fields[i] = b
+	}
+
+	if len(fields[2]) != 8 || len(fields[4]) != 8 {
+		return nil, errors.New("invalid amount or index length")
+	}
+
+	return &DepositEvent{
+		Pubkey:                fields[0],
+		WithdrawalCredentials: fields[1],
+		AmountGwei:            binary.LittleEndian.Uint64(fields[2]),
+		Signature:             fields[3],
+		Index:                 binary.LittleEndian.Uint64(fields[4]),
+	}, nil
+}
@@ -0,0 +1,99 @@
+package jobs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+func packDepositEvent(t *testing.T, pubkey, credentials, amount, signature, index []byte) []byte {
+	t.Helper()
+
+	typ, err := abi.NewType("bytes", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := abi.Arguments{{Type: typ}, {Type: typ}, {Type: typ}, {Type: typ}, {Type: typ}}.Pack(pubkey, credentials, amount, signature, index)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func littleEndian(value uint64) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, value)
+
+	return b
+}
+
+func TestDecodeDepositEvent(t *testing.T) {
+	pubkey := bytes.Repeat([]byte{0xaa}, 48)
+	credentials := append([]byte{0x01}, bytes.Repeat([]byte{0x00}, 31)...)
+	signature := bytes.Repeat([]byte{0xbb}, 96)
+
+	tests := []struct {
+		name       string
+		data       []byte
+		wantAmount uint64
+		wantIndex  uint64
+		wantErr    bool
+	}{
+		{
+			name:       "32 ETH deposit",
+			data:       packDepositEvent(t, pubkey, credentials, littleEndian(32_000_000_000), signature, littleEndian(1_234_567)),
+			wantAmount: 32_000_000_000,
+			wantIndex:  1_234_567,
+		},
+		{
+			name:       "first deposit",
+			data:       packDepositEvent(t, pubkey, credentials, littleEndian(1_000_000_000), signature, littleEndian(0)),
+			wantAmount: 1_000_000_000,
+			wantIndex:  0,
+		},
+		{
+			name:    "invalid amount length",
+			data:    packDepositEvent(t, pubkey, credentials, []byte{0x01, 0x02}, signature, littleEndian(1)),
+			wantErr: true,
+		},
+		{
+			name:    "invalid index length",
+			data:    packDepositEvent(t, pubkey, credentials, littleEndian(1), signature, []byte{}),
+			wantErr: true,
+		},
+		{
+			name:    "truncated data",
+			data:    []byte{0x00, 0x01, 0x02},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeDepositEvent(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeDepositEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got.AmountGwei != tt.wantAmount {
+				t.Errorf("DecodeDepositEvent() amount = %d, want %d", got.AmountGwei, tt.wantAmount)
+			}
+
+			if got.Index != tt.wantIndex {
+				t.Errorf("DecodeDepositEvent() index = %d, want %d", got.Index, tt.wantIndex)
+			}
+
+			if !bytes.Equal(got.Pubkey, pubkey) || !bytes.Equal(got.WithdrawalCredentials, credentials) || !bytes.Equal(got.Signature, signature) {
+				t.Errorf("DecodeDepositEvent() returned unexpected pubkey, credentials or signature")
+			}
+		})
+	}
+}
@@ -26,12 +26,15 @@ type metrics struct {
 	blockMetrics   jobs.BlockMetrics
 	web3Metrics    jobs.Web3
 	netMetrics     jobs.Net
+	contractCalls  jobs.ContractCalls
+	deposit        jobs.DepositContract
+	contractEvents jobs.ContractEvents
 
 	enabledJobs map[string]bool
 }
 
 // NewMetrics creates a new execution Metrics instance
-func NewMetrics(client *ethclient.Client, internalAPI api.ExecutionClient, ethRPCClient *ethrpc.EthRPC, log logrus.FieldLogger, nodeName, namespace string, enabledModules []string) Metrics {
+func NewMetrics(client *ethclient.Client, internalAPI api.ExecutionClient, ethRPCClient *ethrpc.EthRPC, log logrus.FieldLogger, nodeName, namespace string, enabledModules []string, opts Options) Metrics {
 	constLabels := make(prometheus.Labels)
 	constLabels["ethereum_role"] = "execution"
 	constLabels["node_name"] = nodeName
@@ -42,9 +45,12 @@ func NewMetrics(client *ethclient.Client, internalAPI api.ExecutionClient, ethRP
 		syncMetrics:    jobs.NewSyncStatus(client, internalAPI, ethRPCClient, log, namespace, constLabels),
 		txpoolMetrics:  jobs.NewTXPool(client, internalAPI, ethRPCClient, log, namespace, constLabels),
 		adminMetrics:   jobs.NewAdmin(client, internalAPI, ethRPCClient, log, namespace, constLabels),
-		blockMetrics:   jobs.NewBlockMetrics(client, internalAPI, ethRPCClient, log, namespace, constLabels),
+		blockMetrics:   jobs.NewBlockMetrics(client, internalAPI, ethRPCClient, log, namespace, constLabels, opts.Block),
 		web3Metrics:    jobs.NewWeb3(client, internalAPI, ethRPCClient, log, namespace, constLabels),
 		netMetrics:     jobs.NewNet(client, internalAPI, ethRPCClient, log, namespace, constLabels),
+		contractCalls:  jobs.NewContractCalls(client, internalAPI, ethRPCClient, log, namespace, constLabels, opts.ContractCalls),
+		deposit:        jobs.NewDepositContract(client, internalAPI, ethRPCClient, log, namespace, constLabels, opts.DepositContract),
+		contractEvents: jobs.NewContractEvents(client, internalAPI, ethRPCClient, log, namespace, constLabels, opts.ContractEvents),
 
 		enabledJobs: make(map[string]bool),
 	}
@@ -86,6 +92,22 @@ func NewMetrics(client *ethclient.Client, internalAPI api.ExecutionClient, ethRP
 		prometheus.MustRegister(m.blockMetrics.SafeGasLimit)
 		prometheus.MustRegister(m.blockMetrics.SafeGasUsed)
 		prometheus.MustRegister(m.blockMetrics.SafeTransactionCount)
+
+		if opts.Block.BuilderAttribution {
+			m.log.Info("Enabling block builder attribution metrics")
+
+			prometheus.MustRegister(m.blockMetrics.BlocksByFeeRecipient)
+			prometheus.MustRegister(m.blockMetrics.BlocksByExtraData)
+		}
+
+		if opts.Block.Composition {
+			m.log.Info("Enabling block composition metrics")
+
+			prometheus.MustRegister(m.blockMetrics.HeadTransactionsByType)
+			prometheus.MustRegister(m.blockMetrics.HeadBlobCount)
+			prometheus.MustRegister(m.blockMetrics.HeadGasUsedByKind)
+			prometheus.MustRegister(m.blockMetrics.HeadPriorityFee)
+		}
 	}
 
 	if able := jobs.ExporterCanRun(enabledModules, m.txpoolMetrics.RequiredModules()); able {
@@ -118,6 +140,40 @@ func NewMetrics(client *ethclient.Client, internalAPI api.ExecutionClient, ethRP
 		prometheus.MustRegister(m.netMetrics.PeerCount)
 	}
 
+	if able := jobs.ExporterCanRun(enabledModules, m.contractCalls.RequiredModules()); able && m.contractCalls.Enabled() {
+		m.log.Info("Enabling contract call metrics")
+		m.enabledJobs[m.contractCalls.Name()] = true
+
+		prometheus.MustRegister(m.contractCalls.Value)
+		prometheus.MustRegister(m.contractCalls.Address)
+		prometheus.MustRegister(m.contractCalls.Errors)
+	}
+
+	if able := jobs.ExporterCanRun(enabledModules, m.deposit.RequiredModules()); able && opts.DepositContract.Enabled {
+		m.log.Info("Enabling deposit contract metrics")
+		m.enabledJobs[m.deposit.Name()] = true
+
+		prometheus.MustRegister(m.deposit.DepositCount)
+		prometheus.MustRegister(m.deposit.Deposits)
+		prometheus.MustRegister(m.deposit.DepositedGwei)
+		prometheus.MustRegister(m.deposit.WindowCount)
+		prometheus.MustRegister(m.deposit.Requests)
+		prometheus.MustRegister(m.deposit.CursorBlock)
+	}
+
+	// Contract events are driven by new heads observed by the block job.
+	if able := jobs.ExporterCanRun(enabledModules, m.contractEvents.RequiredModules()); able && m.contractEvents.Enabled() {
+		m.log.Info("Enabling contract event metrics")
+		m.enabledJobs[m.contractEvents.Name()] = true
+
+		m.blockMetrics.OnNewHead(m.contractEvents.OnNewHead)
+
+		prometheus.MustRegister(m.contractEvents.Events)
+		prometheus.MustRegister(m.contractEvents.Reorged)
+		prometheus.MustRegister(m.contractEvents.Errors)
+		prometheus.MustRegister(m.contractEvents.SkippedBlocks)
+	}
+
 	return m
 }
 
@@ -150,5 +206,13 @@ func (m *metrics) StartAsync(ctx context.Context) {
 		go m.netMetrics.Start(ctx)
 	}
 
+	if m.enabledJobs[m.contractCalls.Name()] {
+		go m.contractCalls.Start(ctx)
+	}
+
+	if m.enabledJobs[m.deposit.Name()] {
+		go m.deposit.Start(ctx)
+	}
+
 	m.log.Info("Started metrics exporter jobs")
 }
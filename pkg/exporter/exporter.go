@@ -2,15 +2,25 @@ package exporter
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	ehttp "github.com/attestantio/go-eth2-client/http"
 	"github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus"
+	consensusapi "github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/api"
+	consensusjobs "github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/consensus/jobs"
 	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/disk"
 	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/docker"
 	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/execution"
+	execapi "github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/execution/api"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/execution/jobs"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/remotesigner"
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/validator"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 )
@@ -42,8 +52,11 @@ type exporter struct {
 
 	// Exporters
 	execution     execution.Node
+	consensus     consensus.Metrics
 	diskUsage     disk.UsageMetrics
 	dockerMetrics docker.ContainerMetrics
+	validator     validator.ClientMetrics
+	remoteSigner  remotesigner.SignerMetrics
 
 	// Clients
 	beacon beacon.Node
@@ -55,6 +68,17 @@ func (e *exporter) Init(ctx context.Context) error {
 	if e.config.Execution.Enabled {
 		e.log.WithField("modules", strings.Join(e.config.Execution.Modules, ", ")).Info("Initializing execution...")
 
+		var builders []jobs.KnownBuilder
+
+		if e.config.Execution.Builders.MappingFile != "" {
+			mapping, err := jobs.LoadBuilderMapping(e.config.Execution.Builders.MappingFile)
+			if err != nil {
+				return fmt.Errorf("failed to load builder mapping: %w", err)
+			}
+
+			builders = mapping
+		}
+
 		executionNode, err := execution.NewExecutionNode(
 			ctx,
 			e.log.WithField("exporter", "execution"),
@@ -62,6 +86,16 @@ func (e *exporter) Init(ctx context.Context) error {
 			e.config.Execution.Name,
 			e.config.Execution.URL,
 			e.config.Execution.Modules,
+			execution.Options{
+				ContractCalls:   e.config.Execution.ContractCalls,
+				DepositContract: e.config.Execution.DepositContract,
+				ContractEvents:  e.config.Execution.ContractEvents,
+				Block: jobs.BlockMetricsOptions{
+					BuilderAttribution: e.config.Execution.Builders.Enabled,
+					Builders:           builders,
+					Composition:        e.config.Execution.BlockComposition.Enabled,
+				},
+			},
 		)
 		if err != nil {
 			return err
@@ -144,6 +178,55 @@ func (e *exporter) Init(ctx context.Context) error {
 		e.dockerMetrics = dockerMetrics
 	}
 
+	if e.config.Validator.Enabled {
+		e.log.WithField("validator_url", e.config.Validator.URL).Info("Initializing validator client metrics...")
+
+		interval := e.config.Validator.Interval.Duration
+		if interval == 0 {
+			interval = 60 * time.Second
+		}
+
+		validatorMetrics, err := validator.NewClientMetrics(
+			ctx,
+			e.log.WithField("exporter", "validator"),
+			fmt.Sprintf("%s_vc", e.namespace),
+			e.config.Validator.Name,
+			e.config.Validator.URL,
+			e.config.Validator.TokenFile,
+			e.config.Validator.ExpectedKeys,
+			interval,
+		)
+		if err != nil {
+			return err
+		}
+
+		e.validator = validatorMetrics
+	}
+
+	if e.config.RemoteSigner.Enabled {
+		e.log.WithField("remote_signer_url", e.config.RemoteSigner.URL).Info("Initializing remote signer metrics...")
+
+		interval := e.config.RemoteSigner.Interval.Duration
+		if interval == 0 {
+			interval = 30 * time.Second
+		}
+
+		signerMetrics, err := remotesigner.NewSignerMetrics(
+			ctx,
+			e.log.WithField("exporter", "remote_signer"),
+			fmt.Sprintf("%s_remote_signer", e.namespace),
+			e.config.RemoteSigner.Name,
+			e.config.RemoteSigner.URL,
+			e.config.RemoteSigner.ExpectedKeys,
+			interval,
+		)
+		if err != nil {
+			return err
+		}
+
+		e.remoteSigner = signerMetrics
+	}
+
 	return nil
 }
 
@@ -163,6 +246,7 @@ func (e *exporter) Serve(ctx context.Context, port int) error {
 	}
 
 	http.Handle("/metrics", promhttp.Handler())
+	http.HandleFunc("/health", e.serveHealth)
 	http.HandleFunc("/", serveDashboard)
 
 	go func() {
@@ -190,6 +274,18 @@ func (e *exporter) Serve(ctx context.Context, port int) error {
 		go e.dockerMetrics.StartAsync(ctx)
 	}
 
+	if e.config.Validator.Enabled {
+		e.log.WithField("validator_url", e.config.Validator.URL).Info("Starting validator client metrics...")
+
+		go e.validator.StartAsync(ctx)
+	}
+
+	if e.config.RemoteSigner.Enabled {
+		e.log.WithField("remote_signer_url", e.config.RemoteSigner.URL).Info("Starting remote signer metrics...")
+
+		go e.remoteSigner.StartAsync(ctx)
+	}
+
 	if e.config.Consensus.Enabled {
 		e.log.WithField("consensus_url", e.config.Consensus.URL).Info("Starting consensus metrics...")
 
@@ -199,13 +295,19 @@ func (e *exporter) Serve(ctx context.Context, port int) error {
 			return err
 		}
 
+		e.consensus.StartAsync(ctx)
+
+		if handler := e.consensus.EventsHandler(); handler != nil {
+			http.Handle("/events", handler)
+		}
+
 		go e.beacon.StartAsync(ctx)
 	}
 
 	return nil
 }
 
-func (e *exporter) bootstrapConsensusClients(_ context.Context) error {
+func (e *exporter) bootstrapConsensusClients(ctx context.Context) error {
 	opts := *beacon.DefaultOptions().
 		EnablePrometheusMetrics()
 
@@ -223,10 +325,195 @@ func (e *exporter) bootstrapConsensusClients(_ context.Context) error {
 		opts.BeaconSubscription.Enabled = true
 	}
 
-	e.beacon = beacon.NewNode(e.log, &beacon.Config{
-		Addr: e.config.Consensus.URL,
-		Name: e.config.Consensus.Name,
+	if e.config.Consensus.Blobs.Enabled {
+		e.ensureEventTopics(&opts, consensusjobs.BlobsTopics, "blob metrics")
+	}
+
+	if e.config.Consensus.SlotTracker.Enabled {
+		e.ensureEventTopics(&opts, consensusjobs.SlotTrackerTopics, "slot tracker")
+	}
+
+	if e.config.Consensus.ExecutionPayload.Enabled {
+		e.ensureEventTopics(&opts, consensusjobs.ExecutionPayloadTopics, "execution payload metrics")
+	}
+
+	if e.config.Consensus.Participation.Enabled {
+		e.ensureEventTopics(&opts, consensusjobs.ParticipationTopics, "participation metrics")
+	}
+
+	if e.config.Consensus.Operations.Enabled {
+		e.ensureEventTopics(&opts, consensusjobs.OperationsTopics, "operations metrics")
+	}
+
+	headers, err := e.config.Consensus.requestHeaders()
+	if err != nil {
+		return err
+	}
+
+	tlsConfig, err := e.config.Consensus.TLS.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load consensus TLS config: %w", err)
+	}
+
+	if tlsConfig != nil {
+		// The beacon library polls the peers with its own client, which cannot be given the TLS config.
+		e.log.Warn("Consensus TLS is enabled, the peer count metrics of the beacon library are not available")
+
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+
+		opts.AddGoEth2ClientParams(ehttp.WithHTTPClient(&http.Client{
+			Transport: transport,
+		}))
+	}
+
+	consensusAPI := consensusapi.NewConsensusClient(ctx, e.log.WithField("exporter", "consensus"), e.config.Consensus.URL, headers, tlsConfig)
+
+	if opts.BeaconSubscription.Enabled && len(e.config.Consensus.EventStream.Topics) > 0 {
+		if err := e.validateEventTopicForks(ctx, consensusAPI, e.config.Consensus.EventStream.Topics); err != nil {
+			return fmt.Errorf("invalid consensus.eventStream.topics: %w", err)
+		}
+	}
+
+	e.beacon = beacon.NewNode(beaconLogger(e.log), &beacon.Config{
+		Addr:    e.config.Consensus.URL,
+		Name:    e.config.Consensus.Name,
+		Headers: headers,
 	}, "eth_con", opts)
 
+	var eventTopics []string
+	if opts.BeaconSubscription.Enabled {
+		eventTopics = opts.BeaconSubscription.Topics
+	}
+
+	var executionAPI execapi.ExecutionClient
+
+	if e.config.Execution.Enabled {
+		executionAPI = execapi.NewExecutionClient(ctx, e.log.WithField("exporter", "consensus"), e.config.Execution.URL)
+	}
+
+	e.consensus = consensus.NewMetrics(
+		e.beacon,
+		consensusAPI,
+		e.log.WithField("exporter", "consensus"),
+		e.config.Consensus.Name,
+		fmt.Sprintf("%s_con", e.namespace),
+		consensus.Options{
+			Validators:          e.config.Consensus.Validators,
+			SlotTracker:         e.config.Consensus.SlotTracker,
+			EventTiming:         opts.BeaconSubscription.Enabled,
+			EventTopics:         eventTopics,
+			EventSilentEpochs:   e.config.Consensus.EventStream.SilentEpochs,
+			Blobs:               e.config.Consensus.Blobs,
+			Peers:               e.config.Consensus.Peers,
+			Execution:           executionAPI,
+			CheckpointVerifiers: e.checkpointVerifiers(ctx),
+			EventProxy:          e.config.Consensus.EventStream.Proxy,
+			StateSummary:        e.config.Consensus.StateSummary,
+			LightClient:         e.config.Consensus.LightClient,
+			ExecutionPayload:    e.config.Consensus.ExecutionPayload,
+			Participation:       e.config.Consensus.Participation,
+			Operations:          e.config.Consensus.Operations,
+			MevBoost:            e.config.MevBoost,
+		},
+	)
+
 	return nil
 }
+
+// validateEventTopicForks rejects configured topics whose fork is not active on the network of the
+// beacon node. The topics are not checked if the node cannot be reached, as it may still be starting.
+func (e *exporter) validateEventTopicForks(ctx context.Context, consensusAPI consensusapi.ConsensusClient, topics []string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	active, err := activeForks(ctx, consensusAPI)
+	if err != nil {
+		e.log.WithError(err).Warn("Failed to fetch the forks of the network, event stream topics are not checked against them")
+
+		return nil
+	}
+
+	return consensusjobs.ValidateEventTopicForks(topics, active)
+}
+
+// activeForks returns whether a fork is active at the current epoch, based on the fork epochs of
+// the chain spec. Forks the node does not know about are not active.
+func activeForks(ctx context.Context, consensusAPI consensusapi.ConsensusClient) (func(fork string) bool, error) {
+	spec, err := consensusAPI.Spec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch spec: %w", err)
+	}
+
+	genesis, err := consensusAPI.Genesis(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch genesis: %w", err)
+	}
+
+	specUint := func(key string) (uint64, bool) {
+		value, ok := spec[key].(string)
+		if !ok {
+			return 0, false
+		}
+
+		parsed, err := strconv.ParseUint(value, 10, 64)
+
+		return parsed, err == nil
+	}
+
+	secondsPerSlot, ok := specUint("SECONDS_PER_SLOT")
+	if !ok || secondsPerSlot == 0 {
+		return nil, errors.New("spec has no SECONDS_PER_SLOT")
+	}
+
+	slotsPerEpoch, ok := specUint("SLOTS_PER_EPOCH")
+	if !ok || slotsPerEpoch == 0 {
+		return nil, errors.New("spec has no SLOTS_PER_EPOCH")
+	}
+
+	current := uint64(0)
+	if now := uint64(time.Now().Unix()); now > genesis.GenesisTime {
+		current = (now - genesis.GenesisTime) / (secondsPerSlot * slotsPerEpoch)
+	}
+
+	return func(fork string) bool {
+		if fork == "phase0" {
+			return true
+		}
+
+		epoch, ok := specUint(strings.ToUpper(fork) + "_FORK_EPOCH")
+
+		return ok && epoch <= current
+	}, nil
+}
+
+// checkpointVerifiers returns a client for each configured checkpoint verifier, keyed by its host so
+// that credentials in the URL do not end up in metric labels.
+func (e *exporter) checkpointVerifiers(ctx context.Context) map[string]consensusapi.ConsensusClient {
+	verifiers := make(map[string]consensusapi.ConsensusClient)
+
+	// Duplicate names are rejected when the config is validated.
+	urls, _ := e.config.Consensus.checkpointVerifierURLs()
+
+	for name, raw := range urls {
+		verifiers[name] = consensusapi.NewConsensusClient(ctx, e.log.WithField("verifier", name), raw, nil, nil)
+	}
+
+	return verifiers
+}
+
+// ensureEventTopics enables the beacon event stream and subscribes to the topics a job depends on.
+func (e *exporter) ensureEventTopics(opts *beacon.Options, topics []string, job string) {
+	if !opts.BeaconSubscription.Enabled {
+		opts.BeaconSubscription.Enabled = true
+		opts.BeaconSubscription.Topics = beacon.EventTopics{}
+	}
+
+	for _, topic := range topics {
+		if !opts.BeaconSubscription.Topics.Exists(topic) {
+			e.log.WithField("topic", topic).Infof("Adding beacon event stream topic required by the %s", job)
+
+			opts.BeaconSubscription.Topics = append(opts.BeaconSubscription.Topics, topic)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package exporter
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	healthStatusOK        = "ok"
+	healthStatusUnhealthy = "unhealthy"
+)
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// healthChecks returns the health of every subsystem that reports it, keyed by subsystem.
+func (e *exporter) healthChecks() map[string]error {
+	checks := make(map[string]error)
+
+	if e.config.RemoteSigner.Enabled && e.remoteSigner != nil {
+		checks["remote_signer"] = e.remoteSigner.Healthy()
+	}
+
+	return checks
+}
+
+// serveHealth responds with 200 if all subsystems are healthy and 503 otherwise.
+func (e *exporter) serveHealth(w http.ResponseWriter, r *http.Request) {
+	rsp := healthResponse{
+		Status: healthStatusOK,
+		Checks: make(map[string]string),
+	}
+
+	for name, err := range e.healthChecks() {
+		if err != nil {
+			rsp.Status = healthStatusUnhealthy
+			rsp.Checks[name] = err.Error()
+
+			continue
+		}
+
+		rsp.Checks[name] = healthStatusOK
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if rsp.Status != healthStatusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	_ = json.NewEncoder(w).Encode(rsp)
+}
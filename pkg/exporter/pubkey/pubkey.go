@@ -0,0 +1,16 @@
+// Package pubkey holds helpers for validator public keys shared by the validator client and
+// remote signer exporters.
+package pubkey
+
+import "strings"
+
+// Normalize returns the lower case, 0x prefixed form of a pubkey.
+func Normalize(pubkey string) string {
+	pubkey = strings.ToLower(strings.TrimSpace(pubkey))
+
+	if !strings.HasPrefix(pubkey, "0x") {
+		pubkey = "0x" + pubkey
+	}
+
+	return pubkey
+}
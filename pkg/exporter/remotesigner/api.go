@@ -0,0 +1,115 @@
+package remotesigner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client executes Web3Signer API calls.
+type Client interface {
+	// Upcheck returns nil if the signer responds to its liveness check.
+	Upcheck(ctx context.Context) error
+	// Healthcheck returns the health report of the signer.
+	Healthcheck(ctx context.Context) (*Health, error)
+	// PublicKeys returns the eth2 public keys the signer has loaded.
+	PublicKeys(ctx context.Context) ([]string, error)
+}
+
+// Health is the health report of a Web3Signer instance.
+type Health struct {
+	Status  string        `json:"status"`
+	Outcome string        `json:"outcome"`
+	Checks  []HealthCheck `json:"checks"`
+}
+
+// HealthCheck is a single, possibly nested, health check.
+type HealthCheck struct {
+	ID     string        `json:"id"`
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+const statusUp = "UP"
+
+type client struct {
+	url    string
+	client http.Client
+}
+
+// NewClient creates a new Client.
+func NewClient(url string) Client {
+	return &client{
+		url: strings.TrimSuffix(url, "/"),
+		client: http.Client{
+			Timeout: time.Second * 10,
+		},
+	}
+}
+
+func (c *client) get(ctx context.Context, path string, okStatus ...int) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rsp.Body.Close()
+
+	ok := rsp.StatusCode == http.StatusOK
+
+	for _, status := range okStatus {
+		if rsp.StatusCode == status {
+			ok = true
+		}
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("status code: %d", rsp.StatusCode)
+	}
+
+	return io.ReadAll(rsp.Body)
+}
+
+func (c *client) Upcheck(ctx context.Context) error {
+	_, err := c.get(ctx, "/upcheck")
+
+	return err
+}
+
+func (c *client) Healthcheck(ctx context.Context) (*Health, error) {
+	// The signer responds with 503 and a health report when it is unhealthy.
+	data, err := c.get(ctx, "/healthcheck", http.StatusServiceUnavailable)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &Health{}
+	if err := json.Unmarshal(data, rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *client) PublicKeys(ctx context.Context) ([]string, error) {
+	data, err := c.get(ctx, "/api/v1/eth2/publicKeys")
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := []string{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
@@ -0,0 +1,165 @@
+package remotesigner
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// Metrics defines the interface for reporting remote signer metrics.
+type Metrics interface {
+	// ObserveUp reports whether the signer responds to its liveness check.
+	ObserveUp(up bool)
+	// ObserveHealth reports the health report of the signer.
+	ObserveHealth(health *Health)
+	// ObserveKeys reports the status of the keys of the signer.
+	ObserveKeys(keys map[string]bool)
+	// ObserveError reports a failed request.
+	ObserveError(endpoint string)
+}
+
+type metrics struct {
+	log         logrus.FieldLogger
+	up          prometheus.Gauge
+	healthy     prometheus.Gauge
+	checkStatus *prometheus.GaugeVec
+	keys        prometheus.Gauge
+	keyStatus   *prometheus.GaugeVec
+	errors      *prometheus.CounterVec
+}
+
+// NewMetrics returns a new Metrics instance.
+func NewMetrics(log logrus.FieldLogger, namespace, nodeName string) Metrics {
+	constLabels := make(prometheus.Labels)
+	constLabels["ethereum_role"] = "remote_signer"
+	constLabels["node_name"] = nodeName
+
+	m := &metrics{
+		log: log,
+		up: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "up",
+				Help:        "Whether the remote signer responds to its upcheck (1 for up).",
+				ConstLabels: constLabels,
+			},
+		),
+		healthy: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "healthy",
+				Help:        "Whether the remote signer reports itself as healthy (1 for healthy).",
+				ConstLabels: constLabels,
+			},
+		),
+		checkStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "check_status",
+				Help:        "The status of each health check reported by the remote signer (1 for up).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"check",
+			},
+		),
+		keys: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "keys",
+				Help:        "The number of keys loaded by the remote signer.",
+				ConstLabels: constLabels,
+			},
+		),
+		keyStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "key_status",
+				Help:        "Whether each loaded or expected key is loaded by the remote signer (1 for loaded, 0 for expected but missing).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"pubkey",
+			},
+		),
+		errors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "errors_total",
+				Help:        "The number of failed requests to the remote signer.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"endpoint",
+			},
+		),
+	}
+
+	prometheus.MustRegister(
+		m.up,
+		m.healthy,
+		m.checkStatus,
+		m.keys,
+		m.keyStatus,
+		m.errors,
+	)
+
+	return m
+}
+
+func (m *metrics) ObserveUp(up bool) {
+	m.up.Set(boolToFloat(up))
+
+	// A signer that is down is not healthy, and the checks of its last health report are stale.
+	if !up {
+		m.healthy.Set(0)
+		m.checkStatus.Reset()
+	}
+}
+
+func (m *metrics) ObserveHealth(health *Health) {
+	m.healthy.Set(boolToFloat(health.Status == statusUp))
+
+	m.checkStatus.Reset()
+
+	var observe func(prefix string, checks []HealthCheck)
+
+	observe = func(prefix string, checks []HealthCheck) {
+		for _, check := range checks {
+			id := prefix + check.ID
+
+			m.checkStatus.WithLabelValues(id).Set(boolToFloat(check.Status == statusUp))
+
+			observe(id+"/", check.Checks)
+		}
+	}
+
+	observe("", health.Checks)
+}
+
+func (m *metrics) ObserveKeys(keys map[string]bool) {
+	loaded := 0
+
+	m.keyStatus.Reset()
+
+	for pubkey, ok := range keys {
+		if ok {
+			loaded++
+		}
+
+		m.keyStatus.WithLabelValues(pubkey).Set(boolToFloat(ok))
+	}
+
+	m.keys.Set(float64(loaded))
+}
+
+func (m *metrics) ObserveError(endpoint string) {
+	m.errors.WithLabelValues(endpoint).Inc()
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
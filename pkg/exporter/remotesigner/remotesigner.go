@@ -0,0 +1,136 @@
+package remotesigner
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/pubkey"
+	"github.com/sirupsen/logrus"
+)
+
+// SignerMetrics reports health and key inventory metrics of a Web3Signer instance.
+type SignerMetrics interface {
+	// StartAsync starts the remote signer metrics collection.
+	StartAsync(ctx context.Context)
+	// Healthy returns nil if the signer was up and healthy when it was last polled.
+	Healthy() error
+}
+
+type signerMetrics struct {
+	log          logrus.FieldLogger
+	metrics      Metrics
+	api          Client
+	expectedKeys []string
+	interval     time.Duration
+
+	mu     sync.Mutex
+	health error
+}
+
+// ErrNotPolled is reported as the health of a signer that has not been polled yet.
+var ErrNotPolled = errors.New("remote signer has not been polled yet")
+
+// NewSignerMetrics returns a new SignerMetrics instance.
+func NewSignerMetrics(ctx context.Context, log logrus.FieldLogger, namespace, nodeName, url string, expectedKeys []string, interval time.Duration) (SignerMetrics, error) {
+	expected := make([]string, 0, len(expectedKeys))
+
+	for _, key := range expectedKeys {
+		expected = append(expected, pubkey.Normalize(key))
+	}
+
+	return &signerMetrics{
+		log:          log,
+		metrics:      NewMetrics(log, namespace, nodeName),
+		api:          NewClient(url),
+		expectedKeys: expected,
+		interval:     interval,
+		health:       ErrNotPolled,
+	}, nil
+}
+
+func (s *signerMetrics) StartAsync(ctx context.Context) {
+	s.tick(ctx)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.interval):
+				s.tick(ctx)
+			}
+		}
+	}()
+}
+
+func (s *signerMetrics) Healthy() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.health
+}
+
+func (s *signerMetrics) setHealth(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.health = err
+}
+
+func (s *signerMetrics) tick(ctx context.Context) {
+	if err := s.api.Upcheck(ctx); err != nil {
+		s.log.WithError(err).Error("Remote signer upcheck failed")
+
+		s.metrics.ObserveError("upcheck")
+		s.metrics.ObserveUp(false)
+		s.setHealth(err)
+
+		return
+	}
+
+	s.metrics.ObserveUp(true)
+
+	health, err := s.api.Healthcheck(ctx)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get remote signer health")
+
+		s.metrics.ObserveError("healthcheck")
+		s.setHealth(err)
+	} else {
+		s.metrics.ObserveHealth(health)
+
+		if health.Status != statusUp {
+			s.setHealth(errors.New("remote signer reports status " + health.Status))
+		} else {
+			s.setHealth(nil)
+		}
+	}
+
+	keys, err := s.api.PublicKeys(ctx)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to get remote signer public keys")
+
+		s.metrics.ObserveError("public_keys")
+
+		return
+	}
+
+	s.metrics.ObserveKeys(s.keyStatus(keys))
+}
+
+// keyStatus returns the loaded keys and the expected keys, mapped to whether they are loaded.
+func (s *signerMetrics) keyStatus(keys []string) map[string]bool {
+	status := make(map[string]bool, len(keys)+len(s.expectedKeys))
+
+	for _, key := range s.expectedKeys {
+		status[key] = false
+	}
+
+	for _, key := range keys {
+		status[pubkey.Normalize(key)] = true
+	}
+
+	return status
+}
@@ -0,0 +1,187 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when the validator client responds with a 404.
+var ErrNotFound = errors.New("not found")
+
+// KeymanagerClient executes keymanager API calls against a validator client.
+type KeymanagerClient interface {
+	// Keystores returns the local keystores loaded by the validator client.
+	Keystores(ctx context.Context) ([]Keystore, error)
+	// RemoteKeys returns the remote signer keys loaded by the validator client.
+	RemoteKeys(ctx context.Context) ([]RemoteKey, error)
+	// FeeRecipient returns the fee recipient configured for a key.
+	FeeRecipient(ctx context.Context, pubkey string) (string, error)
+	// GasLimit returns the gas limit configured for a key.
+	GasLimit(ctx context.Context, pubkey string) (uint64, error)
+	// Graffiti returns the graffiti configured for a key.
+	Graffiti(ctx context.Context, pubkey string) (string, error)
+}
+
+// Keystore is a local keystore loaded by the validator client.
+type Keystore struct {
+	ValidatingPubkey string `json:"validating_pubkey"`
+	DerivationPath   string `json:"derivation_path"`
+	Readonly         bool   `json:"readonly"`
+}
+
+// RemoteKey is a key whose signing is delegated to a remote signer.
+type RemoteKey struct {
+	Pubkey   string `json:"pubkey"`
+	URL      string `json:"url"`
+	Readonly bool   `json:"readonly"`
+}
+
+type keymanagerClient struct {
+	url       string
+	tokenFile string
+	client    http.Client
+}
+
+// NewKeymanagerClient creates a new KeymanagerClient. The bearer token is read from the token
+// file on every request, so that tokens regenerated by the validator client are picked up.
+func NewKeymanagerClient(url, tokenFile string) KeymanagerClient {
+	return &keymanagerClient{
+		url:       strings.TrimSuffix(url, "/"),
+		tokenFile: tokenFile,
+		client: http.Client{
+			Timeout: time.Second * 10,
+		},
+	}
+}
+
+type apiResponse struct {
+	Data json.RawMessage `json:"data"`
+}
+
+func (c *keymanagerClient) get(ctx context.Context, path string) (json.RawMessage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if c.tokenFile != "" {
+		token, err := os.ReadFile(c.tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keymanager token: %w", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	rsp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code: %d", rsp.StatusCode)
+	}
+
+	data, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(apiResponse)
+	if err := json.Unmarshal(data, resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+func (c *keymanagerClient) Keystores(ctx context.Context) ([]Keystore, error) {
+	data, err := c.get(ctx, "/eth/v1/keystores")
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := []Keystore{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *keymanagerClient) RemoteKeys(ctx context.Context) ([]RemoteKey, error) {
+	data, err := c.get(ctx, "/eth/v1/remotekeys")
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := []RemoteKey{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp, nil
+}
+
+func (c *keymanagerClient) FeeRecipient(ctx context.Context, pubkey string) (string, error) {
+	data, err := c.get(ctx, fmt.Sprintf("/eth/v1/validator/%s/feerecipient", pubkey))
+	if err != nil {
+		return "", err
+	}
+
+	rsp := struct {
+		EthAddress string `json:"ethaddress"`
+	}{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return "", err
+	}
+
+	return rsp.EthAddress, nil
+}
+
+func (c *keymanagerClient) GasLimit(ctx context.Context, pubkey string) (uint64, error) {
+	data, err := c.get(ctx, fmt.Sprintf("/eth/v1/validator/%s/gas_limit", pubkey))
+	if err != nil {
+		return 0, err
+	}
+
+	rsp := struct {
+		GasLimit uint64 `json:"gas_limit,string"`
+	}{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return 0, err
+	}
+
+	return rsp.GasLimit, nil
+}
+
+func (c *keymanagerClient) Graffiti(ctx context.Context, pubkey string) (string, error) {
+	data, err := c.get(ctx, fmt.Sprintf("/eth/v1/validator/%s/graffiti", pubkey))
+	if err != nil {
+		return "", err
+	}
+
+	rsp := struct {
+		Graffiti string `json:"graffiti"`
+	}{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return "", err
+	}
+
+	return rsp.Graffiti, nil
+}
@@ -0,0 +1,235 @@
+package validator
+
+import (
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/pubkey"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// Metrics defines the interface for reporting validator client metrics.
+type Metrics interface {
+	// ObserveInventory reports the keys loaded by the validator client.
+	ObserveInventory(inventory *Inventory)
+	// ObserveUp reports whether the keymanager API is reachable.
+	ObserveUp(up bool)
+	// ObserveError reports a failed keymanager API request.
+	ObserveError(endpoint string)
+}
+
+type metrics struct {
+	log            logrus.FieldLogger
+	up             prometheus.Gauge
+	keystores      prometheus.Gauge
+	remoteKeys     prometheus.Gauge
+	keyInfo        *prometheus.GaugeVec
+	feeRecipient   *prometheus.GaugeVec
+	gasLimit       *prometheus.GaugeVec
+	graffiti       *prometheus.GaugeVec
+	expectedLoaded *prometheus.GaugeVec
+	unexpectedKeys prometheus.Gauge
+	duplicateKeys  prometheus.Gauge
+	errors         *prometheus.CounterVec
+}
+
+// NewMetrics returns a new Metrics instance.
+func NewMetrics(log logrus.FieldLogger, namespace, nodeName string) Metrics {
+	constLabels := make(prometheus.Labels)
+	constLabels["ethereum_role"] = "validator"
+	constLabels["node_name"] = nodeName
+
+	m := &metrics{
+		log: log,
+		up: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "up",
+				Help:        "Whether the keymanager API of the validator client is reachable (1 for reachable).",
+				ConstLabels: constLabels,
+			},
+		),
+		keystores: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "keystores",
+				Help:        "The number of local keystores loaded by the validator client.",
+				ConstLabels: constLabels,
+			},
+		),
+		remoteKeys: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "remote_keys",
+				Help:        "The number of remote signer keys loaded by the validator client.",
+				ConstLabels: constLabels,
+			},
+		),
+		keyInfo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "key_info",
+				Help:        "The keys loaded by the validator client. Keys loaded more than once across a fleet can be found with count by (pubkey).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"pubkey",
+				"type",
+				"readonly",
+			},
+		),
+		feeRecipient: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "fee_recipient_info",
+				Help:        "The fee recipient configured for each key.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"pubkey",
+				"fee_recipient",
+			},
+		),
+		gasLimit: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "gas_limit",
+				Help:        "The gas limit configured for each key.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"pubkey",
+			},
+		),
+		graffiti: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "graffiti_info",
+				Help:        "The graffiti configured for each key.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"pubkey",
+				"graffiti",
+			},
+		),
+		expectedLoaded: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "expected_key_loaded",
+				Help:        "Whether each expected key is loaded by the validator client (1 for loaded).",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"pubkey",
+			},
+		),
+		unexpectedKeys: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "unexpected_keys",
+				Help:        "The number of loaded keys that are not in the expected key list.",
+				ConstLabels: constLabels,
+			},
+		),
+		duplicateKeys: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Name:        "duplicate_keys",
+				Help:        "The number of keys loaded both as a local keystore and as a remote key.",
+				ConstLabels: constLabels,
+			},
+		),
+		errors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   namespace,
+				Name:        "keymanager_errors_total",
+				Help:        "The number of failed keymanager API requests.",
+				ConstLabels: constLabels,
+			},
+			[]string{
+				"endpoint",
+			},
+		),
+	}
+
+	prometheus.MustRegister(
+		m.up,
+		m.keystores,
+		m.remoteKeys,
+		m.keyInfo,
+		m.feeRecipient,
+		m.gasLimit,
+		m.graffiti,
+		m.expectedLoaded,
+		m.unexpectedKeys,
+		m.duplicateKeys,
+		m.errors,
+	)
+
+	return m
+}
+
+func (m *metrics) ObserveUp(up bool) {
+	if up {
+		m.up.Set(1)
+	} else {
+		m.up.Set(0)
+	}
+}
+
+func (m *metrics) ObserveError(endpoint string) {
+	m.errors.WithLabelValues(endpoint).Inc()
+}
+
+func (m *metrics) ObserveInventory(inventory *Inventory) {
+	m.keystores.Set(float64(len(inventory.Keystores)))
+	m.remoteKeys.Set(float64(len(inventory.RemoteKeys)))
+
+	m.keyInfo.Reset()
+
+	for _, key := range inventory.Keystores {
+		m.keyInfo.WithLabelValues(pubkey.Normalize(key.ValidatingPubkey), keyTypeLocal, boolLabel(key.Readonly)).Set(1)
+	}
+
+	for _, key := range inventory.RemoteKeys {
+		m.keyInfo.WithLabelValues(pubkey.Normalize(key.Pubkey), keyTypeRemote, boolLabel(key.Readonly)).Set(1)
+	}
+
+	m.feeRecipient.Reset()
+	m.gasLimit.Reset()
+	m.graffiti.Reset()
+
+	for pubkey, config := range inventory.Configs {
+		if config.FeeRecipient != "" {
+			m.feeRecipient.WithLabelValues(pubkey, config.FeeRecipient).Set(1)
+		}
+
+		if config.GasLimit > 0 {
+			m.gasLimit.WithLabelValues(pubkey).Set(float64(config.GasLimit))
+		}
+
+		if config.Graffiti != "" {
+			m.graffiti.WithLabelValues(pubkey, config.Graffiti).Set(1)
+		}
+	}
+
+	m.expectedLoaded.Reset()
+
+	for pubkey, loaded := range inventory.Expected {
+		if loaded {
+			m.expectedLoaded.WithLabelValues(pubkey).Set(1)
+		} else {
+			m.expectedLoaded.WithLabelValues(pubkey).Set(0)
+		}
+	}
+
+	m.unexpectedKeys.Set(float64(inventory.Unexpected))
+	m.duplicateKeys.Set(float64(inventory.Duplicates))
+}
+
+func boolLabel(b bool) string {
+	if b {
+		return "true"
+	}
+
+	return "false"
+}
@@ -0,0 +1,279 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethpandaops/ethereum-metrics-exporter/pkg/exporter/pubkey"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	keyTypeLocal  = "local"
+	keyTypeRemote = "remote"
+
+	// keyConfigInterval is how often the configuration of already known keys is refreshed. It
+	// changes rarely and takes several requests per key, so it is fetched less often than the keys.
+	keyConfigInterval = 15 * time.Minute
+	// maxKeyConfigRequests bounds the number of keys whose configuration is fetched concurrently.
+	maxKeyConfigRequests = 8
+)
+
+// ClientMetrics reports validator client metrics from the keymanager API.
+type ClientMetrics interface {
+	// StartAsync starts the validator client metrics collection.
+	StartAsync(ctx context.Context)
+	// GetInventory returns the keys loaded by the validator client and their configuration.
+	GetInventory(ctx context.Context) (*Inventory, error)
+}
+
+// Inventory contains the keys loaded by a validator client.
+type Inventory struct {
+	Keystores  []Keystore
+	RemoteKeys []RemoteKey
+
+	// Configs holds the per-key configuration, keyed by the normalized pubkey.
+	Configs map[string]KeyConfig
+	// Expected holds whether each expected key is loaded, keyed by the normalized pubkey.
+	Expected map[string]bool
+	// Unexpected is the number of loaded keys that are not expected. Always 0 without an expected key list.
+	Unexpected int
+	// Duplicates is the number of keys that are loaded both locally and remotely.
+	Duplicates int
+}
+
+// KeyConfig is the proposer configuration of a single key.
+type KeyConfig struct {
+	FeeRecipient string
+	GasLimit     uint64
+	Graffiti     string
+}
+
+// cachedKeyConfig is a cached key configuration. It is incomplete if any of its requests failed,
+// in which case it holds the previous values of the failed fields and is fetched again next tick.
+type cachedKeyConfig struct {
+	config   KeyConfig
+	complete bool
+}
+
+type clientMetrics struct {
+	log          logrus.FieldLogger
+	metrics      Metrics
+	api          KeymanagerClient
+	expectedKeys map[string]bool
+	interval     time.Duration
+
+	mu *sync.Mutex
+	// configs caches the configuration of the loaded keys, keyed by the normalized pubkey.
+	configs map[string]cachedKeyConfig
+	// configsRefreshed is when the configuration of every loaded key was last fetched.
+	configsRefreshed time.Time
+}
+
+// NewClientMetrics returns a new ClientMetrics instance.
+func NewClientMetrics(ctx context.Context, log logrus.FieldLogger, namespace, nodeName, url, tokenFile string, expectedKeys []string, interval time.Duration) (ClientMetrics, error) {
+	expected := make(map[string]bool, len(expectedKeys))
+
+	for _, key := range expectedKeys {
+		expected[pubkey.Normalize(key)] = true
+	}
+
+	return &clientMetrics{
+		log:          log,
+		metrics:      NewMetrics(log, namespace, nodeName),
+		api:          NewKeymanagerClient(url, tokenFile),
+		expectedKeys: expected,
+		interval:     interval,
+		mu:           &sync.Mutex{},
+		configs:      make(map[string]cachedKeyConfig),
+	}, nil
+}
+
+func (c *clientMetrics) StartAsync(ctx context.Context) {
+	c.tick(ctx)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.interval):
+				c.tick(ctx)
+			}
+		}
+	}()
+}
+
+func (c *clientMetrics) tick(ctx context.Context) {
+	inventory, err := c.GetInventory(ctx)
+	if err != nil {
+		c.log.WithError(err).Error("Failed to get validator client keys")
+
+		c.metrics.ObserveUp(false)
+
+		return
+	}
+
+	c.metrics.ObserveUp(true)
+	c.metrics.ObserveInventory(inventory)
+}
+
+func (c *clientMetrics) GetInventory(ctx context.Context) (*Inventory, error) {
+	keystores, err := c.api.Keystores(ctx)
+	if err != nil {
+		c.metrics.ObserveError("keystores")
+
+		return nil, err
+	}
+
+	remoteKeys, err := c.api.RemoteKeys(ctx)
+	if err != nil {
+		// Not every validator client implements the remote keys endpoints.
+		if !errors.Is(err, ErrNotFound) {
+			c.metrics.ObserveError("remote_keys")
+
+			return nil, err
+		}
+
+		remoteKeys = []RemoteKey{}
+	}
+
+	inventory := &Inventory{
+		Keystores:  keystores,
+		RemoteKeys: remoteKeys,
+		Expected:   make(map[string]bool, len(c.expectedKeys)),
+	}
+
+	loaded := make(map[string]int)
+
+	for _, key := range keystores {
+		loaded[pubkey.Normalize(key.ValidatingPubkey)]++
+	}
+
+	for _, key := range remoteKeys {
+		loaded[pubkey.Normalize(key.Pubkey)]++
+	}
+
+	for pubkey, count := range loaded {
+		if count > 1 {
+			inventory.Duplicates++
+		}
+
+		if len(c.expectedKeys) > 0 && !c.expectedKeys[pubkey] {
+			inventory.Unexpected++
+		}
+	}
+
+	inventory.Configs = c.keyConfigs(ctx, loaded)
+
+	for pubkey := range c.expectedKeys {
+		inventory.Expected[pubkey] = loaded[pubkey] > 0
+	}
+
+	return inventory, nil
+}
+
+// keyConfigs returns the configuration of the loaded keys. The configuration of new keys, and of
+// keys whose last fetch failed, is fetched right away, the configuration of other keys only every
+// keyConfigInterval.
+func (c *clientMetrics) keyConfigs(ctx context.Context, loaded map[string]int) map[string]KeyConfig {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	refresh := time.Since(c.configsRefreshed) >= keyConfigInterval
+	if refresh {
+		c.configsRefreshed = time.Now()
+	}
+
+	cached := make(map[string]cachedKeyConfig, len(loaded))
+	fetched := &sync.Mutex{}
+	wg := &sync.WaitGroup{}
+	sem := make(chan struct{}, maxKeyConfigRequests)
+
+	for pubkey := range loaded {
+		previous, ok := c.configs[pubkey]
+		if ok && previous.complete && !refresh {
+			cached[pubkey] = previous
+
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(pubkey string, previous KeyConfig) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			config, complete := c.keyConfig(ctx, pubkey, previous)
+
+			fetched.Lock()
+			cached[pubkey] = cachedKeyConfig{config: config, complete: complete}
+			fetched.Unlock()
+		}(pubkey, previous.config)
+	}
+
+	wg.Wait()
+
+	// Keys that are no longer loaded are dropped from the cache.
+	c.configs = cached
+
+	configs := make(map[string]KeyConfig, len(cached))
+	for pubkey, entry := range cached {
+		configs[pubkey] = entry.config
+	}
+
+	return configs
+}
+
+// keyConfig fetches the proposer configuration of a key. Fields whose request failed keep their
+// previous value, and the configuration is reported as incomplete so that it is fetched again.
+// Missing endpoints are not failures, as not every validator client implements every endpoint.
+func (c *clientMetrics) keyConfig(ctx context.Context, pubkey string, previous KeyConfig) (KeyConfig, bool) {
+	config := previous
+	complete := true
+
+	if feeRecipient, err := c.api.FeeRecipient(ctx, pubkey); err == nil {
+		config.FeeRecipient = strings.ToLower(feeRecipient)
+	} else if !c.observeKeyError("fee_recipient", pubkey, err) {
+		config.FeeRecipient = ""
+	} else {
+		complete = false
+	}
+
+	if gasLimit, err := c.api.GasLimit(ctx, pubkey); err == nil {
+		config.GasLimit = gasLimit
+	} else if !c.observeKeyError("gas_limit", pubkey, err) {
+		config.GasLimit = 0
+	} else {
+		complete = false
+	}
+
+	if graffiti, err := c.api.Graffiti(ctx, pubkey); err == nil {
+		config.Graffiti = graffiti
+	} else if !c.observeKeyError("graffiti", pubkey, err) {
+		config.Graffiti = ""
+	} else {
+		complete = false
+	}
+
+	return config, complete
+}
+
+// observeKeyError counts a failed key configuration request. It returns false if the endpoint is
+// not implemented, which is not a failure.
+func (c *clientMetrics) observeKeyError(endpoint, pubkey string, err error) bool {
+	if errors.Is(err, ErrNotFound) {
+		return false
+	}
+
+	c.metrics.ObserveError(endpoint)
+
+	c.log.WithError(err).WithField("pubkey", pubkey).WithField("endpoint", endpoint).Debug("Failed to get key configuration")
+
+	return true
+}